@@ -0,0 +1,104 @@
+package core
+
+import (
+	"github.com/docker-pet/backend/models"
+	pbCore "github.com/pocketbase/pocketbase/core"
+)
+
+// Audit actions
+const (
+	AuditUserRoleChanged        = "user.role_changed"
+	AuditUserPremiumChanged     = "user.premium_changed"
+	AuditOtpConfirmed           = "otp.confirmed"
+	AuditOutlineSettingsChanged = "outline.settings_changed"
+	AuditOutlineTokenRotated    = "outline.token_rotated"
+	AuditConfigRolledBack       = "config.rolled_back"
+	AuditSecretRotated          = "secret.rotated"
+	AuditAccountDeleted         = "account.deleted"
+	AuditUserBanned             = "user.banned"
+	AuditUserUnbanned           = "user.unbanned"
+	AuditUserRoleOverridden     = "user.role_overridden"
+	AuditUserPremiumGranted     = "user.premium_granted"
+	AuditUserPremiumExpired     = "user.premium_expired"
+	AuditSubscriptionGranted    = "subscription.granted"
+	AuditSubscriptionRevoked    = "subscription.revoked"
+	AuditSubscriptionExpired    = "subscription.expired"
+	AuditOutlineServerEnabled   = "outline.server_enabled"
+	AuditOutlineServerDisabled  = "outline.server_disabled"
+	AuditOutlineServerResynced  = "outline.server_resynced"
+	AuditBotAdminCommand        = "telegram_bot.admin_command"
+)
+
+// Actor labels used when an action is not performed by a user.
+const (
+	AuditActorSystem   = "system"
+	AuditActorTelegram = "telegram"
+)
+
+// AuditEntry describes a security-relevant action. Before and After hold
+// the changed values only and must never contain secrets.
+type AuditEntry struct {
+	Module     string
+	Action     string
+	ActorId    string // users record id, empty for non-user actors
+	ActorLabel string // e.g. "system", "telegram" or a superuser email
+	TargetId   string // users record id the action was applied to
+	Before     any
+	After      any
+	IP         string
+}
+
+// WithRequest fills the actor and the client IP from the request.
+func (entry AuditEntry) WithRequest(e *pbCore.RequestEvent) AuditEntry {
+	entry.IP = e.RealIP()
+
+	if e.Auth != nil {
+		if e.HasSuperuserAuth() {
+			entry.ActorLabel = "superuser:" + e.Auth.Email()
+		} else {
+			entry.ActorId = e.Auth.Id
+		}
+	}
+
+	return entry
+}
+
+// Audit stores the entry in the audit_log collection. Failures are logged
+// and returned, but callers usually should not abort the audited action.
+// The entry is written outside of any transaction, use AuditWith to have it
+// rolled back together with the audited change.
+func (ctx *AppContext) Audit(entry AuditEntry) error {
+	return ctx.AuditWith(ctx.App, entry)
+}
+
+// AuditWith stores the entry like Audit, with the given app, e.g. the
+// txApp of RunInTransaction.
+func (ctx *AppContext) AuditWith(app pbCore.App, entry AuditEntry) error {
+	collection, err := app.FindCollectionByNameOrId("audit_log")
+	if err == nil {
+		record := &models.AuditEntry{}
+		record.SetProxyRecord(pbCore.NewRecord(collection))
+		record.SetModule(entry.Module)
+		record.SetAction(entry.Action)
+		record.SetActorId(entry.ActorId)
+		record.SetActorLabel(entry.ActorLabel)
+		record.SetTargetId(entry.TargetId)
+		record.SetBefore(entry.Before)
+		record.SetAfter(entry.After)
+		record.SetIP(entry.IP)
+
+		err = app.Save(record)
+	}
+
+	if err != nil {
+		app.Logger().Error(
+			"Failed to write audit log entry",
+			"Error", err,
+			"Module", entry.Module,
+			"Action", entry.Action,
+			"TargetId", entry.TargetId,
+		)
+	}
+
+	return err
+}
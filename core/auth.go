@@ -0,0 +1,32 @@
+package core
+
+import (
+	"github.com/docker-pet/backend/models"
+	pbCore "github.com/pocketbase/pocketbase/core"
+	"github.com/pocketbase/pocketbase/tools/hook"
+)
+
+// IsAdmin reports whether the request is made by a superuser or by a user
+// with the admin role.
+func IsAdmin(e *pbCore.RequestEvent) bool {
+	if e.Auth == nil {
+		return false
+	}
+	if e.HasSuperuserAuth() {
+		return true
+	}
+	return e.Auth.Collection().Name == "users" && e.Auth.GetString("role") == string(models.RoleAdmin)
+}
+
+// RequireAdmin is a route middleware allowing superusers and admin users.
+func RequireAdmin() *hook.Handler[*pbCore.RequestEvent] {
+	return &hook.Handler[*pbCore.RequestEvent]{
+		Id: "requireAdmin",
+		Func: func(e *pbCore.RequestEvent) error {
+			if !IsAdmin(e) {
+				return e.ForbiddenError("Only admins can perform this action.", nil)
+			}
+			return e.Next()
+		},
+	}
+}
@@ -0,0 +1,145 @@
+package core
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+
+	"gopkg.in/yaml.v3"
+)
+
+// ModuleSettings wraps a module Config with an enablement flag. The Config
+// fields are inlined, so a module section in the config file looks like:
+//
+//	lampa:
+//	  enabled: true
+//	  storagePath: ./generated/lampa
+type ModuleSettings[T any] struct {
+	Enabled bool `yaml:"enabled"`
+	Config  T    `yaml:",inline"`
+}
+
+// ConfigValidator is implemented by module configs that can check their
+// values. InitModules validates every registered config before Init.
+type ConfigValidator interface {
+	Validate() error
+}
+
+// LoadConfig fills target from a YAML file and then from environment
+// variables. A missing file is not an error. ${VAR} references in the file
+// are expanded, and every field can be overridden by a variable named after
+// its YAML path, e.g. PREFIX_MODULES_LAMPA_STORAGE_PATH.
+func LoadConfig(path string, envPrefix string, target any) error {
+	if path != "" {
+		content, err := os.ReadFile(path)
+		if err != nil && !os.IsNotExist(err) {
+			return fmt.Errorf("failed to read config file %q: %w", path, err)
+		}
+
+		if err == nil {
+			expanded := os.ExpandEnv(string(content))
+			if err := yaml.Unmarshal([]byte(expanded), target); err != nil {
+				return fmt.Errorf("failed to parse config file %q: %w", path, err)
+			}
+		}
+	}
+
+	return applyEnv(reflect.ValueOf(target).Elem(), envPrefix)
+}
+
+var durationType = reflect.TypeFor[time.Duration]()
+
+func applyEnv(value reflect.Value, name string) error {
+	if value.Kind() == reflect.Struct {
+		for i := 0; i < value.NumField(); i++ {
+			field := value.Type().Field(i)
+			if !field.IsExported() {
+				continue
+			}
+
+			tag, _, _ := strings.Cut(field.Tag.Get("yaml"), ",")
+			if tag == "-" {
+				continue
+			}
+
+			fieldName := name
+			if !strings.Contains(field.Tag.Get("yaml"), "inline") {
+				if tag == "" {
+					tag = field.Name
+				}
+				fieldName = name + "_" + envName(tag)
+			}
+
+			if err := applyEnv(value.Field(i), fieldName); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	raw, ok := os.LookupEnv(name)
+	if !ok {
+		return nil
+	}
+
+	var err error
+	switch {
+	case value.Type() == durationType:
+		var d time.Duration
+		if d, err = time.ParseDuration(raw); err == nil {
+			value.SetInt(int64(d))
+		}
+	case value.Kind() == reflect.String:
+		value.SetString(raw)
+	case value.Kind() == reflect.Bool:
+		var b bool
+		if b, err = strconv.ParseBool(raw); err == nil {
+			value.SetBool(b)
+		}
+	case value.CanInt():
+		var n int64
+		if n, err = strconv.ParseInt(raw, 10, 64); err == nil {
+			value.SetInt(n)
+		}
+	default:
+		err = errors.New("unsupported field type " + value.Type().String())
+	}
+
+	if err != nil {
+		return fmt.Errorf("invalid value of %s: %w", name, err)
+	}
+	return nil
+}
+
+// envName converts a camelCase YAML key into UPPER_SNAKE_CASE.
+func envName(key string) string {
+	var b strings.Builder
+	runes := []rune(key)
+	for i, r := range runes {
+		if unicode.IsUpper(r) && i > 0 && (unicode.IsLower(runes[i-1]) ||
+			(i+1 < len(runes) && unicode.IsLower(runes[i+1]) && unicode.IsUpper(runes[i-1]))) {
+			b.WriteByte('_')
+		}
+		b.WriteRune(unicode.ToUpper(r))
+	}
+	return b.String()
+}
+
+func validateConfigs(entries []*ModuleEntry) error {
+	var errs []error
+	for _, entry := range entries {
+		validator, ok := entry.Config.(ConfigValidator)
+		if !ok {
+			continue
+		}
+		if err := validator.Validate(); err != nil {
+			errs = append(errs, fmt.Errorf("invalid %s config: %w", entry.Module.Name(), err))
+		}
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,24 @@
+package core
+
+import (
+	"fmt"
+
+	"github.com/docker-pet/backend/models"
+)
+
+// AppConfigChanged is published after the app config record is updated.
+// Old holds the values before the update.
+type AppConfigChanged struct {
+	Old *models.AppConfig
+	New *models.AppConfig
+}
+
+// Changed reports whether any of the given record fields differ.
+func (e AppConfigChanged) Changed(fields ...string) bool {
+	for _, field := range fields {
+		if fmt.Sprint(e.Old.Get(field)) != fmt.Sprint(e.New.Get(field)) {
+			return true
+		}
+	}
+	return false
+}
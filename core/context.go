@@ -1,23 +1,43 @@
 package core
 
 import (
+	"reflect"
 	"time"
 
+	"github.com/docker-pet/backend/i18n"
 	"github.com/pocketbase/pocketbase/core"
-	"resty.dev/v3"
+	"github.com/prometheus/client_golang/prometheus"
 )
 
 type AppContext struct {
-	App        core.App
-	HttpClient *resty.Client
-	Modules    map[string]Module
+	App         core.App
+	HttpClients *HttpClients
+	I18n        *i18n.Catalog // User-facing strings, admin overrides are loaded by the translations module
+
+	ShutdownTimeout time.Duration // Deadline for stopping all modules, defaults to 10s
+
+	Metrics       *prometheus.Registry // Backend metrics, modules register their collectors here
+	MetricsSecret string               // Bearer token for GET /metrics, generated if not set
+
+	initializing  Module                  // Module whose Init is currently running
+	modulesByName map[string]Module       // Initialized modules keyed by their name
+	modulesByType map[reflect.Type]Module // Initialized modules keyed by their concrete type
+	entries       []*ModuleEntry          // Initialized modules in dependency order
+	events        EventBus
 }
 
-func NewHttpClient() *resty.Client {
-	client := resty.New()
-	client.SetRetryCount(3)
-	client.SetRetryWaitTime(1 * time.Second)
-	client.SetRetryMaxWaitTime(5 * time.Second)
-	client.SetTimeout(7 * time.Second)
-	return client
+func (ctx *AppContext) initModule(entry *ModuleEntry) (err error) {
+	ctx.initializing = entry.Module
+	defer func() {
+		ctx.initializing = nil
+		if r := recover(); r != nil {
+			depErr, ok := r.(*DependencyError)
+			if !ok {
+				panic(r)
+			}
+			err = depErr
+		}
+	}()
+
+	return entry.Module.Init(ctx, ctx.App.Logger().WithGroup(entry.Module.Name()), entry.Config)
 }
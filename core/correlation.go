@@ -0,0 +1,63 @@
+package core
+
+import (
+	"context"
+	"log/slog"
+	"regexp"
+
+	pbCore "github.com/pocketbase/pocketbase/core"
+	"github.com/pocketbase/pocketbase/tools/security"
+)
+
+// RequestIdHeader carries the correlation id of an HTTP request. A valid
+// incoming value (e.g. set by a reverse proxy) is kept, otherwise a new one
+// is generated; the id is always echoed in the response.
+const RequestIdHeader = "X-Request-Id"
+
+type correlationIdKey struct{}
+
+var validCorrelationId = regexp.MustCompile(`^[A-Za-z0-9._:-]{1,128}$`)
+
+// NewCorrelationId returns a random id with a prefix describing the source,
+// e.g. "req", "tg" or "cron".
+func NewCorrelationId(prefix string) string {
+	return prefix + "-" + security.RandomString(16)
+}
+
+// WithCorrelationId returns a context carrying the correlation id.
+func WithCorrelationId(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, correlationIdKey{}, id)
+}
+
+// CorrelationId returns the correlation id of the context or "".
+func CorrelationId(ctx context.Context) string {
+	id, _ := ctx.Value(correlationIdKey{}).(string)
+	return id
+}
+
+// Logger returns base annotated with the correlation id of ctx, so every
+// line logged while handling a request, update or cron run can be traced.
+func Logger(ctx context.Context, base *slog.Logger) *slog.Logger {
+	if id := CorrelationId(ctx); id != "" {
+		return base.With("CorrelationId", id)
+	}
+	return base
+}
+
+// bindRequestId assigns a correlation id to every HTTP request.
+func bindRequestId(ctx *AppContext) {
+	ctx.App.OnServe().BindFunc(func(se *pbCore.ServeEvent) error {
+		se.Router.BindFunc(func(e *pbCore.RequestEvent) error {
+			id := e.Request.Header.Get(RequestIdHeader)
+			if !validCorrelationId.MatchString(id) {
+				id = NewCorrelationId("req")
+			}
+
+			e.Request = e.Request.WithContext(WithCorrelationId(e.Request.Context(), id))
+			e.Response.Header().Set(RequestIdHeader, id)
+			return e.Next()
+		})
+
+		return se.Next()
+	})
+}
@@ -0,0 +1,58 @@
+package core
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"sync"
+)
+
+// EventBus dispatches typed domain events between modules. Handlers run
+// synchronously in the publisher's goroutine, in subscription order, so
+// slow handlers should hand work off (e.g. to a debounced job).
+type EventBus struct {
+	mu       sync.RWMutex
+	handlers map[reflect.Type][]func(any) error
+}
+
+// Subscribe registers a handler for events of type E. Modules subscribe
+// during Init.
+func Subscribe[E any](ctx *AppContext, handler func(event E) error) {
+	bus := &ctx.events
+	bus.mu.Lock()
+	defer bus.mu.Unlock()
+
+	if bus.handlers == nil {
+		bus.handlers = map[reflect.Type][]func(any) error{}
+	}
+
+	eventType := reflect.TypeFor[E]()
+	bus.handlers[eventType] = append(bus.handlers[eventType], func(event any) error {
+		return handler(event.(E))
+	})
+}
+
+// Publish delivers the event to every subscriber of its type. All handlers
+// are called even if some of them fail; failures are logged and returned
+// joined together.
+func Publish[E any](ctx *AppContext, event E) error {
+	eventType := reflect.TypeFor[E]()
+
+	ctx.events.mu.RLock()
+	handlers := ctx.events.handlers[eventType]
+	ctx.events.mu.RUnlock()
+
+	var errs []error
+	for _, handler := range handlers {
+		if err := handler(event); err != nil {
+			ctx.App.Logger().Warn(
+				"Event handler failed",
+				"Event", eventType.String(),
+				"Error", err,
+			)
+			errs = append(errs, fmt.Errorf("%s handler: %w", eventType, err))
+		}
+	}
+
+	return errors.Join(errs...)
+}
@@ -0,0 +1,92 @@
+package core
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	pbCore "github.com/pocketbase/pocketbase/core"
+)
+
+const readinessTimeout = 5 * time.Second
+
+// HealthChecker is implemented by modules that can report whether they are
+// functional. A nil error means the module is ready.
+type HealthChecker interface {
+	HealthCheck(ctx context.Context) error
+}
+
+type ModuleStatus struct {
+	Status string `json:"status"`
+	Error  string `json:"error,omitempty"` // Only returned to admins
+}
+
+type ReadinessReport struct {
+	Status  string                  `json:"status"`
+	Modules map[string]ModuleStatus `json:"modules"`
+}
+
+// CheckReadiness runs the health checks of all initialized modules.
+func (ctx *AppContext) CheckReadiness(c context.Context) *ReadinessReport {
+	report := &ReadinessReport{
+		Status:  "ok",
+		Modules: map[string]ModuleStatus{},
+	}
+
+	for _, entry := range ctx.entries {
+		status := ModuleStatus{Status: "ok"}
+		if checker, ok := entry.Module.(HealthChecker); ok {
+			if err := checker.HealthCheck(c); err != nil {
+				status = ModuleStatus{Status: "fail", Error: err.Error()}
+				report.Status = "fail"
+			}
+		}
+		report.Modules[entry.Module.Name()] = status
+	}
+
+	return report
+}
+
+// WithoutErrors returns the report with the status of each check only, error
+// details may reveal file paths and upstream responses.
+func (r *ReadinessReport) WithoutErrors() *ReadinessReport {
+	report := &ReadinessReport{
+		Status:  r.Status,
+		Modules: make(map[string]ModuleStatus, len(r.Modules)),
+	}
+	for name, status := range r.Modules {
+		report.Modules[name] = ModuleStatus{Status: status.Status}
+	}
+	return report
+}
+
+// bindHealthEndpoints exposes the aggregated readiness report. Failed checks
+// are logged, their errors are only returned to admins. Liveness is served
+// by the PocketBase built-in GET /api/health.
+func bindHealthEndpoints(ctx *AppContext) {
+	ctx.App.OnServe().BindFunc(func(se *pbCore.ServeEvent) error {
+		se.Router.GET("/api/ready", func(e *pbCore.RequestEvent) error {
+			checkCtx, cancel := context.WithTimeout(e.Request.Context(), readinessTimeout)
+			defer cancel()
+
+			report := ctx.CheckReadiness(checkCtx)
+			for name, status := range report.Modules {
+				if status.Error != "" {
+					Logger(e.Request.Context(), ctx.App.Logger()).Warn("Readiness check failed", "Module", name, "Error", status.Error)
+				}
+			}
+			if !IsAdmin(e) {
+				report = report.WithoutErrors()
+			}
+
+			e.Response.Header().Set("Cache-Control", "no-cache, no-store, must-revalidate")
+
+			if report.Status != "ok" {
+				return e.JSON(http.StatusServiceUnavailable, report)
+			}
+			return e.JSON(http.StatusOK, report)
+		})
+
+		return se.Next()
+	})
+}
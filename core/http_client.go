@@ -0,0 +1,165 @@
+package core
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"time"
+
+	"resty.dev/v3"
+)
+
+// Outbound HTTP client profiles. Unknown names fall back to the default one.
+const (
+	HttpProfileDefault     = "default"
+	HttpProfileCaddyAdmin  = "caddy-admin"
+	HttpProfileAvatarFetch = "avatar-fetch"
+	HttpProfileMetrics     = "metrics"
+)
+
+// HttpClientProfile is the policy of the clients of one integration.
+// Retries are only made for idempotent methods.
+type HttpClientProfile struct {
+	Timeout           time.Duration `yaml:"timeout"`
+	RetryCount        int           `yaml:"retryCount"`
+	RetryWaitTime     time.Duration `yaml:"retryWaitTime"`
+	RetryMaxWaitTime  time.Duration `yaml:"retryMaxWaitTime"`
+	ResponseBodyLimit int64         `yaml:"responseBodyLimit"` // Bytes, 0 means unlimited
+	TLS               HttpClientTLS `yaml:"tls"`
+}
+
+// HttpClientTLS enables mTLS and/or a custom CA bundle. Empty paths keep
+// the system defaults.
+type HttpClientTLS struct {
+	CertFile string `yaml:"certFile"`
+	KeyFile  string `yaml:"keyFile"`
+	CAFile   string `yaml:"caFile"`
+}
+
+func DefaultHttpClientProfile() HttpClientProfile {
+	return HttpClientProfile{
+		Timeout:          7 * time.Second,
+		RetryCount:       3,
+		RetryWaitTime:    1 * time.Second,
+		RetryMaxWaitTime: 5 * time.Second,
+	}
+}
+
+// HttpClients builds resty clients from named profiles. A non-nil transport
+// replaces the network transport of every client, which lets tests point
+// the modules at local stand-ins.
+type HttpClients struct {
+	profiles  map[string]HttpClientProfile
+	transport http.RoundTripper
+	clients   map[string]*resty.Client
+}
+
+func NewHttpClients(profiles map[string]HttpClientProfile, transport http.RoundTripper) (*HttpClients, error) {
+	c := &HttpClients{
+		profiles:  map[string]HttpClientProfile{HttpProfileDefault: DefaultHttpClientProfile()},
+		transport: transport,
+		clients:   map[string]*resty.Client{},
+	}
+	for name, profile := range profiles {
+		c.profiles[name] = profile
+	}
+
+	var errs []error
+	for name := range c.profiles {
+		client, err := c.NewClient(name, nil)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		c.clients[name] = client
+	}
+
+	if err := errors.Join(errs...); err != nil {
+		c.Close()
+		return nil, err
+	}
+	return c, nil
+}
+
+// Client returns the shared client of the profile.
+func (c *HttpClients) Client(name string) *resty.Client {
+	if client, ok := c.clients[name]; ok {
+		return client
+	}
+	return c.clients[HttpProfileDefault]
+}
+
+// NewClient returns a new client of the profile that the caller must close.
+// transport overrides the network transport, e.g. to dial a unix socket.
+func (c *HttpClients) NewClient(name string, transport http.RoundTripper) (*resty.Client, error) {
+	profile, ok := c.profiles[name]
+	if !ok {
+		profile = c.profiles[HttpProfileDefault]
+	}
+
+	if transport == nil {
+		transport = c.transport
+	}
+	if transport == nil {
+		httpTransport, err := profile.TLS.transport()
+		if err != nil {
+			return nil, fmt.Errorf("http client profile %s: %w", name, err)
+		}
+		transport = httpTransport
+	}
+
+	client := resty.New().
+		SetTransport(transport).
+		SetTimeout(profile.Timeout).
+		SetRetryCount(profile.RetryCount).
+		SetRetryWaitTime(profile.RetryWaitTime).
+		SetRetryMaxWaitTime(profile.RetryMaxWaitTime).
+		SetAllowNonIdempotentRetry(false)
+
+	if profile.ResponseBodyLimit > 0 {
+		client.SetResponseBodyLimit(profile.ResponseBodyLimit)
+	}
+
+	return client, nil
+}
+
+func (c *HttpClients) Close() {
+	for _, client := range c.clients {
+		client.Close()
+	}
+}
+
+func (t HttpClientTLS) transport() (*http.Transport, error) {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	if t.CertFile == "" && t.KeyFile == "" && t.CAFile == "" {
+		return transport, nil
+	}
+
+	config := &tls.Config{MinVersion: tls.VersionTLS12}
+
+	if t.CertFile != "" || t.KeyFile != "" {
+		certificate, err := tls.LoadX509KeyPair(t.CertFile, t.KeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load client certificate: %w", err)
+		}
+		config.Certificates = []tls.Certificate{certificate}
+	}
+
+	if t.CAFile != "" {
+		pem, err := os.ReadFile(t.CAFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read CA file: %w", err)
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("no certificates found in CA file %q", t.CAFile)
+		}
+		config.RootCAs = pool
+	}
+
+	transport.TLSClientConfig = config
+	return transport, nil
+}
@@ -0,0 +1,122 @@
+package core
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"resty.dev/v3"
+)
+
+// stubTransport answers requests with the responses in order, the last one
+// is repeated.
+type stubTransport struct {
+	responses []stubResponse
+	calls     int
+}
+
+type stubResponse struct {
+	status int
+	body   string
+}
+
+func (t *stubTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	response := t.responses[min(t.calls, len(t.responses)-1)]
+	t.calls++
+
+	return &http.Response{
+		StatusCode: response.status,
+		Status:     http.StatusText(response.status),
+		Header:     http.Header{"Content-Type": []string{"text/plain"}},
+		Body:       io.NopCloser(strings.NewReader(response.body)),
+		Request:    req,
+	}, nil
+}
+
+func newStubClients(t *testing.T, profile HttpClientProfile, transport *stubTransport) *HttpClients {
+	t.Helper()
+
+	clients, err := NewHttpClients(map[string]HttpClientProfile{"stub": profile}, transport)
+	if err != nil {
+		t.Fatalf("NewHttpClients() error = %v", err)
+	}
+	t.Cleanup(clients.Close)
+	return clients
+}
+
+func TestHttpClientProfileRetries(t *testing.T) {
+	profile := HttpClientProfile{
+		Timeout:          time.Second,
+		RetryCount:       2,
+		RetryWaitTime:    time.Millisecond,
+		RetryMaxWaitTime: time.Millisecond,
+	}
+	responses := []stubResponse{
+		{status: http.StatusServiceUnavailable},
+		{status: http.StatusServiceUnavailable},
+		{status: http.StatusOK, body: "ok"},
+	}
+
+	t.Run("idempotent", func(t *testing.T) {
+		transport := &stubTransport{responses: responses}
+		response, err := newStubClients(t, profile, transport).Client("stub").R().Get("http://stub/")
+		if err != nil {
+			t.Fatalf("Get() error = %v", err)
+		}
+		if response.StatusCode() != http.StatusOK || response.String() != "ok" {
+			t.Errorf("Get() = %d %q, want 200 \"ok\"", response.StatusCode(), response.String())
+		}
+		if transport.calls != 3 {
+			t.Errorf("calls = %d, want 3", transport.calls)
+		}
+	})
+
+	t.Run("non-idempotent", func(t *testing.T) {
+		transport := &stubTransport{responses: responses}
+		response, err := newStubClients(t, profile, transport).Client("stub").R().Post("http://stub/")
+		if err != nil {
+			t.Fatalf("Post() error = %v", err)
+		}
+		if response.StatusCode() != http.StatusServiceUnavailable {
+			t.Errorf("Post() status = %d, want 503", response.StatusCode())
+		}
+		if transport.calls != 1 {
+			t.Errorf("calls = %d, want 1", transport.calls)
+		}
+	})
+}
+
+func TestHttpClientProfileResponseBodyLimit(t *testing.T) {
+	profile := HttpClientProfile{Timeout: time.Second, ResponseBodyLimit: 8}
+
+	t.Run("within limit", func(t *testing.T) {
+		transport := &stubTransport{responses: []stubResponse{{status: http.StatusOK, body: "12345678"}}}
+		response, err := newStubClients(t, profile, transport).Client("stub").R().Get("http://stub/")
+		if err != nil {
+			t.Fatalf("Get() error = %v", err)
+		}
+		if response.String() != "12345678" {
+			t.Errorf("Get() body = %q, want %q", response.String(), "12345678")
+		}
+	})
+
+	t.Run("over limit", func(t *testing.T) {
+		transport := &stubTransport{responses: []stubResponse{{status: http.StatusOK, body: "123456789"}}}
+		_, err := newStubClients(t, profile, transport).Client("stub").R().Get("http://stub/")
+		if !errors.Is(err, resty.ErrReadExceedsThresholdLimit) {
+			t.Errorf("Get() error = %v, want %v", err, resty.ErrReadExceedsThresholdLimit)
+		}
+	})
+}
+
+func TestHttpClientsUnknownProfile(t *testing.T) {
+	transport := &stubTransport{responses: []stubResponse{{status: http.StatusOK}}}
+	clients := newStubClients(t, HttpClientProfile{}, transport)
+
+	if clients.Client("unknown") != clients.Client(HttpProfileDefault) {
+		t.Error("Client() of an unknown profile is not the default client")
+	}
+}
@@ -0,0 +1,22 @@
+package core
+
+import (
+	"github.com/docker-pet/backend/i18n"
+	pbCore "github.com/pocketbase/pocketbase/core"
+)
+
+// RequestLocale resolves the locale of a request: the language of the
+// authenticated user, then the Accept-Language header, then the fallback.
+func (ctx *AppContext) RequestLocale(e *pbCore.RequestEvent) string {
+	var candidates []string
+	if e.Auth != nil && e.Auth.Collection().Name == "users" {
+		candidates = append(candidates, e.Auth.GetString("language"))
+	}
+	candidates = append(candidates, i18n.ParseAcceptLanguage(e.Request.Header.Get("Accept-Language"))...)
+	return ctx.I18n.Match(candidates...)
+}
+
+// T translates the key into the locale of the request.
+func (ctx *AppContext) T(e *pbCore.RequestEvent, key string, args ...i18n.Args) string {
+	return ctx.I18n.T(ctx.RequestLocale(e), key, args...)
+}
@@ -0,0 +1,75 @@
+package core
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"time"
+
+	pbCore "github.com/pocketbase/pocketbase/core"
+)
+
+const defaultShutdownTimeout = 10 * time.Second
+
+// Starter is implemented by modules that need to run background work once
+// the HTTP server is up (pollers, workers, initial builds).
+type Starter interface {
+	Start(ctx context.Context) error
+}
+
+// Stopper is implemented by modules that own goroutines, cron jobs or
+// debounced jobs which must be released on shutdown or App.Restart().
+type Stopper interface {
+	Stop(ctx context.Context) error
+}
+
+// bindLifecycle starts modules in dependency order after the server is
+// serving and stops them in reverse order when the app terminates.
+func bindLifecycle(ctx *AppContext, entries []*ModuleEntry) {
+	started := []*ModuleEntry{}
+
+	ctx.App.OnServe().BindFunc(func(e *pbCore.ServeEvent) error {
+		if err := e.Next(); err != nil {
+			return err
+		}
+
+		for _, entry := range entries {
+			if starter, ok := entry.Module.(Starter); ok {
+				if err := starter.Start(context.Background()); err != nil {
+					return fmt.Errorf("failed to start module %s: %w", entry.Module.Name(), err)
+				}
+			}
+			started = append(started, entry)
+		}
+
+		return nil
+	})
+
+	ctx.App.OnTerminate().BindFunc(func(e *pbCore.TerminateEvent) error {
+		timeout := ctx.ShutdownTimeout
+		if timeout <= 0 {
+			timeout = defaultShutdownTimeout
+		}
+
+		stopCtx, cancel := context.WithTimeout(context.Background(), timeout)
+		defer cancel()
+
+		for _, entry := range slices.Backward(started) {
+			stopper, ok := entry.Module.(Stopper)
+			if !ok {
+				continue
+			}
+
+			if err := stopper.Stop(stopCtx); err != nil {
+				ctx.App.Logger().Warn(
+					"Failed to stop module",
+					"Module", entry.Module.Name(),
+					"Error", err,
+				)
+			}
+		}
+		started = nil
+
+		return e.Next()
+	})
+}
@@ -0,0 +1,111 @@
+package core
+
+import (
+	"crypto/subtle"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	pbCore "github.com/pocketbase/pocketbase/core"
+	"github.com/pocketbase/pocketbase/tools/router"
+	"github.com/pocketbase/pocketbase/tools/security"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+// MetricsNamespace prefixes every backend metric. Modules register their
+// own collectors in ctx.Metrics with a subsystem named after the module.
+const MetricsNamespace = "docker_pet"
+
+// MetricsPath is served with the backend metrics. Requests must carry the
+// metrics secret as a bearer token.
+const MetricsPath = "/metrics"
+
+func NewMetricsRegistry() *prometheus.Registry {
+	registry := prometheus.NewRegistry()
+	registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+	)
+	return registry
+}
+
+type httpMetrics struct {
+	requests *prometheus.CounterVec
+	duration *prometheus.HistogramVec
+}
+
+// bindMetrics instruments every HTTP request and exposes the registry.
+func bindMetrics(ctx *AppContext) {
+	metrics := &httpMetrics{
+		requests: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: MetricsNamespace,
+			Subsystem: "http",
+			Name:      "requests_total",
+			Help:      "HTTP requests by route, method and status code.",
+		}, []string{"route", "method", "status"}),
+		duration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: MetricsNamespace,
+			Subsystem: "http",
+			Name:      "request_duration_seconds",
+			Help:      "HTTP request latencies by route and method.",
+			Buckets:   prometheus.DefBuckets,
+		}, []string{"route", "method"}),
+	}
+	ctx.Metrics.MustRegister(metrics.requests, metrics.duration)
+
+	handler := promhttp.HandlerFor(ctx.Metrics, promhttp.HandlerOpts{})
+
+	ctx.App.OnServe().BindFunc(func(se *pbCore.ServeEvent) error {
+		se.Router.BindFunc(metrics.observe)
+
+		se.Router.GET(MetricsPath, func(e *pbCore.RequestEvent) error {
+			token, _ := strings.CutPrefix(e.Request.Header.Get("Authorization"), "Bearer ")
+			if subtle.ConstantTimeCompare([]byte(token), []byte(ctx.MetricsSecret)) != 1 {
+				return e.UnauthorizedError("Invalid metrics secret", nil)
+			}
+
+			handler.ServeHTTP(e.Response, e.Request)
+			return nil
+		})
+
+		return se.Next()
+	})
+}
+
+func (metrics *httpMetrics) observe(e *pbCore.RequestEvent) error {
+	start := time.Now()
+	err := e.Next()
+
+	// The pattern keeps path parameters (ids, secrets) out of the labels
+	route := e.Request.Pattern
+	if _, path, found := strings.Cut(route, " "); found {
+		route = path
+	}
+	if route == "" {
+		route = "unmatched"
+	}
+
+	status := e.Status()
+	if err != nil {
+		status = router.ToApiError(err).Status
+	}
+	if status == 0 {
+		status = http.StatusOK
+	}
+
+	metrics.requests.WithLabelValues(route, e.Request.Method, strconv.Itoa(status)).Inc()
+	metrics.duration.WithLabelValues(route, e.Request.Method).Observe(time.Since(start).Seconds())
+	return err
+}
+
+func ensureMetrics(ctx *AppContext) {
+	if ctx.Metrics == nil {
+		ctx.Metrics = NewMetricsRegistry()
+	}
+	if ctx.MetricsSecret == "" {
+		ctx.MetricsSecret = security.RandomString(32)
+	}
+}
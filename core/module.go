@@ -3,7 +3,10 @@ package core
 import (
 	"fmt"
 	"log/slog"
-    pbCore "github.com/pocketbase/pocketbase/core"
+	"slices"
+	"strings"
+
+	pbCore "github.com/pocketbase/pocketbase/core"
 )
 
 type Module interface {
@@ -18,56 +21,124 @@ type ModuleEntry struct {
 	Config any
 }
 
-var moduleRegistry = map[string]*ModuleEntry{}
+// Modules are kept in registration order so that the resolved init order
+// does not depend on map iteration.
+var moduleRegistry = []*ModuleEntry{}
 
 func RegisterModule(m Module, config any) {
-	moduleRegistry[m.Name()] = &ModuleEntry{Module: m, Config: config}
+	for i, entry := range moduleRegistry {
+		if entry.Module.Name() == m.Name() {
+			moduleRegistry[i] = &ModuleEntry{Module: m, Config: config}
+			return
+		}
+	}
+	moduleRegistry = append(moduleRegistry, &ModuleEntry{Module: m, Config: config})
 }
 
-func InitModules(ctx *AppContext) error {
-	visited := map[string]bool{}
-
-    ctx.App.OnBootstrap().BindFunc(func(e *pbCore.BootstrapEvent) error {
-        if err := e.Next(); err != nil {
-            return err
-        }
+// ResolveModules returns the registered modules sorted topologically by
+// their dependencies, including optional ones that are registered. Modules
+// without a dependency relation keep their registration order, so the
+// result is the same on every run.
+func ResolveModules() ([]*ModuleEntry, error) {
+	entries := map[string]*ModuleEntry{}
+	for _, entry := range moduleRegistry {
+		entries[entry.Module.Name()] = entry
+	}
 
-		for name, entry := range moduleRegistry {
-			entry.Module.SetLogger(ctx.App.Logger().WithGroup(name))
-		}
+	const (
+		unvisited = iota
+		visiting
+		done
+	)
 
-		return nil
-    })
+	state := map[string]int{}
+	sorted := make([]*ModuleEntry, 0, len(moduleRegistry))
+	var path []string
 
-	var initModule func(name string) error
-	initModule = func(name string) error {
-		if visited[name] {
+	var visit func(name string) error
+	visit = func(name string) error {
+		switch state[name] {
+		case done:
 			return nil
+		case visiting:
+			cycle := append(path[slices.Index(path, name):], name)
+			return fmt.Errorf("module dependency cycle: %s", strings.Join(cycle, " -> "))
 		}
-		entry, ok := moduleRegistry[name]
-		if !ok {
-			return fmt.Errorf("module not found: %s", name)
-		}
+
+		entry := entries[name]
+		state[name] = visiting
+		path = append(path, name)
+
 		for _, dep := range entry.Module.Deps() {
-			if err := initModule(dep); err != nil {
+			if _, ok := entries[dep]; !ok {
+				return fmt.Errorf("module dependency not found: %s (required by %s)", dep, strings.Join(path, " -> "))
+			}
+			if err := visit(dep); err != nil {
 				return err
 			}
 		}
-		if err := entry.Module.Init(ctx, ctx.App.Logger().WithGroup(name), entry.Config); err != nil {
-			return err
-		}
-		if ctx.Modules == nil {
-			ctx.Modules = map[string]Module{}
+
+		if dependent, ok := entry.Module.(OptionalDependent); ok {
+			for _, dep := range dependent.OptionalDeps() {
+				if _, ok := entries[dep]; !ok {
+					continue
+				}
+				if err := visit(dep); err != nil {
+					return err
+				}
+			}
 		}
-		ctx.Modules[name] = entry.Module
-		visited[name] = true
+
+		path = path[:len(path)-1]
+		state[name] = done
+		sorted = append(sorted, entry)
 		return nil
 	}
 
-	for name := range moduleRegistry {
-		if err := initModule(name); err != nil {
+	for _, entry := range moduleRegistry {
+		if err := visit(entry.Module.Name()); err != nil {
+			return nil, err
+		}
+	}
+
+	return sorted, nil
+}
+
+func InitModules(ctx *AppContext) error {
+	entries, err := ResolveModules()
+	if err != nil {
+		return err
+	}
+
+	if err := validateConfigs(entries); err != nil {
+		return err
+	}
+
+	ensureMetrics(ctx)
+	bindRequestId(ctx)
+	bindMetrics(ctx)
+
+	ctx.App.OnBootstrap().BindFunc(func(e *pbCore.BootstrapEvent) error {
+		if err := e.Next(); err != nil {
 			return err
 		}
+
+		for _, entry := range entries {
+			entry.Module.SetLogger(ctx.App.Logger().WithGroup(entry.Module.Name()))
+		}
+
+		return nil
+	})
+
+	for _, entry := range entries {
+		if err := ctx.initModule(entry); err != nil {
+			return fmt.Errorf("failed to init module %s: %w", entry.Module.Name(), err)
+		}
+		ctx.registerInitialized(entry.Module)
 	}
+
+	ctx.entries = entries
+	bindLifecycle(ctx, entries)
+	bindHealthEndpoints(ctx)
 	return nil
 }
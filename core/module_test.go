@@ -0,0 +1,123 @@
+package core
+
+import (
+	"log/slog"
+	"slices"
+	"strings"
+	"testing"
+)
+
+type stubModule struct {
+	name         string
+	deps         []string
+	optionalDeps []string
+}
+
+func (m *stubModule) Name() string                                             { return m.name }
+func (m *stubModule) Deps() []string                                           { return m.deps }
+func (m *stubModule) OptionalDeps() []string                                   { return m.optionalDeps }
+func (m *stubModule) SetLogger(logger *slog.Logger)                            {}
+func (m *stubModule) Init(ctx *AppContext, logger *slog.Logger, cfg any) error { return nil }
+
+// withModules replaces the module registry for the test.
+func withModules(t *testing.T, modules ...*stubModule) {
+	t.Helper()
+
+	registry := moduleRegistry
+	moduleRegistry = []*ModuleEntry{}
+	t.Cleanup(func() { moduleRegistry = registry })
+
+	for _, module := range modules {
+		RegisterModule(module, nil)
+	}
+}
+
+func TestResolveModules(t *testing.T) {
+	tests := []struct {
+		name    string
+		modules []*stubModule
+		want    []string
+		wantErr string
+	}{
+		{
+			name:    "registration order without dependencies",
+			modules: []*stubModule{{name: "a"}, {name: "b"}, {name: "c"}},
+			want:    []string{"a", "b", "c"},
+		},
+		{
+			name: "dependencies first",
+			modules: []*stubModule{
+				{name: "bot", deps: []string{"users", "app"}},
+				{name: "users", deps: []string{"app"}},
+				{name: "app"},
+			},
+			want: []string{"app", "users", "bot"},
+		},
+		{
+			name: "registered optional dependency first",
+			modules: []*stubModule{
+				{name: "bot", optionalDeps: []string{"outline"}},
+				{name: "outline"},
+			},
+			want: []string{"outline", "bot"},
+		},
+		{
+			name: "missing optional dependency skipped",
+			modules: []*stubModule{
+				{name: "bot", optionalDeps: []string{"outline", "lampa"}},
+				{name: "lampa"},
+			},
+			want: []string{"lampa", "bot"},
+		},
+		{
+			name: "missing required dependency",
+			modules: []*stubModule{
+				{name: "bot", deps: []string{"users"}},
+				{name: "users", deps: []string{"app"}},
+			},
+			wantErr: "module dependency not found: app (required by bot -> users)",
+		},
+		{
+			name: "cycle",
+			modules: []*stubModule{
+				{name: "a", deps: []string{"b"}},
+				{name: "b", deps: []string{"c"}},
+				{name: "c", deps: []string{"a"}},
+			},
+			wantErr: "module dependency cycle: a -> b -> c -> a",
+		},
+		{
+			name: "cycle through an optional dependency",
+			modules: []*stubModule{
+				{name: "a", deps: []string{"b"}},
+				{name: "b", optionalDeps: []string{"a"}},
+			},
+			wantErr: "module dependency cycle: a -> b -> a",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			withModules(t, tt.modules...)
+
+			entries, err := ResolveModules()
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("ResolveModules() error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ResolveModules() error = %v", err)
+			}
+
+			got := make([]string, len(entries))
+			for i, entry := range entries {
+				got[i] = entry.Module.Name()
+			}
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("ResolveModules() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,141 @@
+package core
+
+import (
+	"fmt"
+	"reflect"
+	"slices"
+)
+
+// OptionalDependent is implemented by modules that use other modules when
+// they are registered but keep working without them. Optional dependencies
+// take part in the init order only when present.
+type OptionalDependent interface {
+	OptionalDeps() []string
+}
+
+// DependencyError is returned when a module requests another module that is
+// missing or was not declared in Deps() / OptionalDeps().
+type DependencyError struct {
+	Module     string
+	Dependency string
+	Reason     string
+}
+
+func (e *DependencyError) Error() string {
+	return fmt.Sprintf("module %s: dependency %s %s", e.Module, e.Dependency, e.Reason)
+}
+
+// Require stores the initialized module of type T into target. While a
+// module is being initialized the requested module must be declared in its
+// Deps(); a missing module results in an error that fails InitModules.
+func Require[T Module](ctx *AppContext, target *T) error {
+	module, found := ctx.moduleOf(reflect.TypeFor[T]())
+	if !found {
+		return ctx.missingDependencyError(reflect.TypeFor[T](), false)
+	}
+
+	if err := ctx.checkDeclared(module.Name(), false); err != nil {
+		return err
+	}
+
+	*target = module.(T)
+	return nil
+}
+
+// Lookup stores the module of type T into target if it is registered and
+// reports whether it was found. It is meant for optional dependencies,
+// which must be declared in OptionalDeps() or Deps().
+func Lookup[T Module](ctx *AppContext, target *T) bool {
+	module, found := ctx.moduleOf(reflect.TypeFor[T]())
+	if !found {
+		// Declared dependencies are initialized first, a registered one that
+		// isn't is missing from OptionalDeps()
+		if _, registered := registeredModule(reflect.TypeFor[T]()); registered {
+			panic(ctx.missingDependencyError(reflect.TypeFor[T](), true))
+		}
+		return false
+	}
+
+	if err := ctx.checkDeclared(module.Name(), true); err != nil {
+		panic(err)
+	}
+
+	*target = module.(T)
+	return true
+}
+
+// ModuleEnabled reports whether the module with the name is initialized,
+// e.g. to tell clients which features are available.
+func (ctx *AppContext) ModuleEnabled(name string) bool {
+	_, found := ctx.modulesByName[name]
+	return found
+}
+
+func (ctx *AppContext) moduleOf(moduleType reflect.Type) (Module, bool) {
+	module, found := ctx.modulesByType[moduleType]
+	return module, found
+}
+
+// registeredModule finds a module of the type among the registered ones,
+// initialized or not.
+func registeredModule(moduleType reflect.Type) (Module, bool) {
+	for _, entry := range moduleRegistry {
+		if reflect.TypeOf(entry.Module) == moduleType {
+			return entry.Module, true
+		}
+	}
+	return nil, false
+}
+
+func (ctx *AppContext) registerInitialized(module Module) {
+	if ctx.modulesByName == nil {
+		ctx.modulesByName = map[string]Module{}
+	}
+	if ctx.modulesByType == nil {
+		ctx.modulesByType = map[reflect.Type]Module{}
+	}
+
+	ctx.modulesByName[module.Name()] = module
+	ctx.modulesByType[reflect.TypeOf(module)] = module
+}
+
+func (ctx *AppContext) checkDeclared(name string, optional bool) error {
+	if ctx.initializing == nil {
+		return nil
+	}
+
+	if slices.Contains(ctx.initializing.Deps(), name) {
+		return nil
+	}
+
+	if dependent, ok := ctx.initializing.(OptionalDependent); ok && slices.Contains(dependent.OptionalDeps(), name) {
+		return nil
+	}
+
+	if optional {
+		return ctx.dependencyError(name, "is not declared in Deps() or OptionalDeps()")
+	}
+	return ctx.dependencyError(name, "is not declared in Deps()")
+}
+
+// missingDependencyError tells a module that is not registered (disabled)
+// from one that is registered but not initialized yet, which happens when it
+// is missing from Deps() and so may be initialized later.
+func (ctx *AppContext) missingDependencyError(moduleType reflect.Type, optional bool) error {
+	registered, ok := registeredModule(moduleType)
+	if !ok {
+		return ctx.dependencyError(moduleType.String(), "is not registered")
+	}
+	if err := ctx.checkDeclared(registered.Name(), optional); err != nil {
+		return err
+	}
+	return ctx.dependencyError(registered.Name(), "is registered but not initialized yet")
+}
+
+func (ctx *AppContext) dependencyError(dependency string, reason string) error {
+	name := "<runtime>"
+	if ctx.initializing != nil {
+		name = ctx.initializing.Name()
+	}
+	return &DependencyError{Module: name, Dependency: dependency, Reason: reason}
+}
@@ -0,0 +1,22 @@
+package core
+
+import (
+	"time"
+
+	"github.com/docker-pet/backend/models"
+)
+
+// SubscriptionExpiring is published once per period when the last period of
+// a kind ends within the notification window.
+type SubscriptionExpiring struct {
+	User *models.User
+	Kind models.SubscriptionKind
+	Ends time.Time
+}
+
+// SubscriptionExpired is published when the last period of a kind has ended
+// and the user lost the access or premium it granted.
+type SubscriptionExpired struct {
+	User *models.User
+	Kind models.SubscriptionKind
+}
@@ -0,0 +1,41 @@
+package core
+
+import "github.com/docker-pet/backend/models"
+
+// UserActivated is published when a user gets a non-guest role, either on
+// creation or on update.
+type UserActivated struct {
+	User *models.User
+}
+
+// UserDeactivated is published when an active user becomes a guest or is
+// deleted.
+type UserDeactivated struct {
+	User    *models.User
+	Deleted bool
+}
+
+// PremiumChanged is published when the premium flag of a user changes.
+type PremiumChanged struct {
+	User    *models.User
+	Premium bool
+}
+
+// OutlineTokenRotated is published when the outline access token of an
+// existing user is replaced.
+type OutlineTokenRotated struct {
+	User *models.User
+}
+
+// OutlineServerChanged is published when a user selects another outline
+// server.
+type OutlineServerChanged struct {
+	User             *models.User
+	PreviousServerId string
+}
+
+// AccessExpiryChanged is published when the end of the access period of a
+// user changes.
+type AccessExpiryChanged struct {
+	User *models.User
+}
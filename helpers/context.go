@@ -0,0 +1,19 @@
+package helpers
+
+import "context"
+
+// WaitContext runs fn in a goroutine and waits until it returns or ctx is done.
+func WaitContext(ctx context.Context, fn func()) error {
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		fn()
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
@@ -65,3 +65,18 @@ func RemoveFileIfExists(path string) error {
 	}
 	return nil
 }
+
+// CheckWritable verifies that a file can be created inside the directory.
+func CheckWritable(dirPath string) error {
+	if err := EnsureDir(dirPath); err != nil {
+		return fmt.Errorf("failed to create dir %q: %w", dirPath, err)
+	}
+
+	file, err := os.CreateTemp(dirPath, ".write-check-*")
+	if err != nil {
+		return fmt.Errorf("dir %q is not writable: %w", dirPath, err)
+	}
+	file.Close()
+
+	return os.Remove(file.Name())
+}
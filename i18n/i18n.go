@@ -0,0 +1,253 @@
+// Package i18n holds the message catalogs for user-facing strings.
+//
+// Catalogs are embedded YAML files, one per locale, mapping message keys to
+// a text or to plural forms (one, few, many, other). Texts may contain
+// {name} placeholders, the "count" argument also selects the plural form.
+package i18n
+
+import (
+	"embed"
+	"fmt"
+	"path"
+	"slices"
+	"strings"
+	"sync"
+
+	"gopkg.in/yaml.v3"
+)
+
+// BaseLocale is the last locale of every fallback chain, all keys must be
+// present in its catalog.
+const BaseLocale = "en"
+
+//go:embed locales/*.yaml
+var localesFS embed.FS
+
+// Args are the placeholder values of a message.
+type Args map[string]any
+
+// Message is a translation with its plural forms, a plain text is stored as
+// the "other" form.
+type Message map[string]string
+
+func (msg *Message) UnmarshalYAML(node *yaml.Node) error {
+	if node.Kind == yaml.ScalarNode {
+		*msg = Message{PluralOther: node.Value}
+		return nil
+	}
+
+	forms := map[string]string{}
+	if err := node.Decode(&forms); err != nil {
+		return err
+	}
+	*msg = forms
+	return nil
+}
+
+// Catalog translates message keys. It is safe for concurrent use.
+type Catalog struct {
+	fallbackLocale string
+
+	mu        sync.RWMutex
+	messages  map[string]map[string]Message // Embedded messages keyed by locale and key
+	overrides map[string]map[string]Message // Admin overrides keyed by locale and key
+}
+
+// NewCatalog loads the embedded catalogs. Messages missing in a locale are
+// taken from fallbackLocale and then from BaseLocale.
+func NewCatalog(fallbackLocale string) (*Catalog, error) {
+	if fallbackLocale == "" {
+		fallbackLocale = BaseLocale
+	}
+
+	c := &Catalog{
+		messages:  map[string]map[string]Message{},
+		overrides: map[string]map[string]Message{},
+	}
+
+	files, err := localesFS.ReadDir("locales")
+	if err != nil {
+		return nil, err
+	}
+	for _, file := range files {
+		data, err := localesFS.ReadFile(path.Join("locales", file.Name()))
+		if err != nil {
+			return nil, err
+		}
+
+		messages := map[string]Message{}
+		if err := yaml.Unmarshal(data, &messages); err != nil {
+			return nil, fmt.Errorf("locale %s: %w", file.Name(), err)
+		}
+		c.messages[strings.TrimSuffix(file.Name(), path.Ext(file.Name()))] = messages
+	}
+
+	c.fallbackLocale = c.Match(fallbackLocale)
+	if c.fallbackLocale != NormalizeLocale(fallbackLocale) {
+		return nil, fmt.Errorf("fallback locale %q has no catalog", fallbackLocale)
+	}
+	return c, nil
+}
+
+// Locales lists the locales with an embedded catalog or overrides.
+func (c *Catalog) Locales() []string {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	locales := make([]string, 0, len(c.messages))
+	for locale := range c.messages {
+		locales = append(locales, locale)
+	}
+	for locale := range c.overrides {
+		if !slices.Contains(locales, locale) {
+			locales = append(locales, locale)
+		}
+	}
+	slices.Sort(locales)
+	return locales
+}
+
+// FallbackLocale is used when none of the requested locales has a catalog.
+func (c *Catalog) FallbackLocale() string {
+	return c.fallbackLocale
+}
+
+// Match returns the first candidate with a catalog, trying the language
+// without its region too ("pt-br" → "pt"), or the fallback locale.
+func (c *Catalog) Match(candidates ...string) string {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	for _, candidate := range candidates {
+		locale := NormalizeLocale(candidate)
+		if c.hasLocale(locale) {
+			return locale
+		}
+		if language, _, found := strings.Cut(locale, "-"); found && c.hasLocale(language) {
+			return language
+		}
+	}
+	if c.fallbackLocale != "" {
+		return c.fallbackLocale
+	}
+	return BaseLocale
+}
+
+// T translates the key into the best matching locale. Unknown keys are
+// returned as is.
+func (c *Catalog) T(locale string, key string, args ...Args) string {
+	var merged Args
+	if len(args) > 0 {
+		merged = Args{}
+		for _, a := range args {
+			for name, value := range a {
+				merged[name] = value
+			}
+		}
+	}
+
+	locale = c.Match(locale)
+
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	for _, candidate := range c.chain(locale) {
+		if msg, ok := c.lookup(candidate, key); ok {
+			return format(msg.form(candidate, merged), merged)
+		}
+	}
+	return key
+}
+
+// SetOverrides replaces the admin overrides, keyed by locale and key.
+func (c *Catalog) SetOverrides(overrides map[string]map[string]Message) {
+	normalized := map[string]map[string]Message{}
+	for locale, messages := range overrides {
+		normalized[NormalizeLocale(locale)] = messages
+	}
+
+	c.mu.Lock()
+	c.overrides = normalized
+	c.mu.Unlock()
+}
+
+func (c *Catalog) chain(locale string) []string {
+	chain := []string{locale}
+	for _, next := range []string{c.fallbackLocale, BaseLocale} {
+		if next != "" && !slices.Contains(chain, next) {
+			chain = append(chain, next)
+		}
+	}
+	return chain
+}
+
+func (c *Catalog) hasLocale(locale string) bool {
+	_, embedded := c.messages[locale]
+	_, overridden := c.overrides[locale]
+	return embedded || overridden
+}
+
+func (c *Catalog) lookup(locale string, key string) (Message, bool) {
+	if msg, ok := c.overrides[locale][key]; ok && len(msg) > 0 {
+		return msg, true
+	}
+	msg, ok := c.messages[locale][key]
+	return msg, ok
+}
+
+// form picks the plural form for the "count" argument.
+func (msg Message) form(locale string, args Args) string {
+	if count, ok := args["count"]; ok {
+		if text, ok := msg[PluralForm(locale, toInt(count))]; ok {
+			return text
+		}
+	}
+	return msg[PluralOther]
+}
+
+func format(text string, args Args) string {
+	if len(args) == 0 {
+		return text
+	}
+
+	pairs := make([]string, 0, len(args)*2)
+	for name, value := range args {
+		pairs = append(pairs, "{"+name+"}", fmt.Sprint(value))
+	}
+	return strings.NewReplacer(pairs...).Replace(text)
+}
+
+func toInt(value any) int {
+	switch v := value.(type) {
+	case int:
+		return v
+	case int64:
+		return int(v)
+	case int32:
+		return int(v)
+	case uint:
+		return int(v)
+	case float64:
+		return int(v)
+	}
+	return 0
+}
+
+// NormalizeLocale lowercases a language tag and uses "-" as the separator,
+// so "pt_BR" and "pt-BR" both become "pt-br".
+func NormalizeLocale(locale string) string {
+	return strings.ReplaceAll(strings.ToLower(strings.TrimSpace(locale)), "_", "-")
+}
+
+// ParseAcceptLanguage lists the locales of an Accept-Language header in
+// the order given by the client, ignoring quality values.
+func ParseAcceptLanguage(header string) []string {
+	var locales []string
+	for _, part := range strings.Split(header, ",") {
+		locale, _, _ := strings.Cut(part, ";")
+		if locale = strings.TrimSpace(locale); locale != "" && locale != "*" {
+			locales = append(locales, locale)
+		}
+	}
+	return locales
+}
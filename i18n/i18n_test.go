@@ -0,0 +1,50 @@
+package i18n
+
+import "testing"
+
+func TestCatalogT(t *testing.T) {
+	catalog, err := NewCatalog("ru")
+	if err != nil {
+		t.Fatalf("NewCatalog() error = %v", err)
+	}
+	catalog.SetOverrides(map[string]map[string]Message{
+		"ru": {
+			"secret_keys.body_invalid":  {PluralOther: "Переопределено"},
+			"secret_keys.rotate_failed": {},
+			"test.fallback":             {PluralOther: "Запасной"},
+			"test.days":                 {PluralOne: "{count} день", PluralFew: "{count} дня", PluralMany: "{count} дней"},
+		},
+		"en": {
+			"test.base": {PluralOther: "Base {name}"},
+		},
+	})
+
+	tests := []struct {
+		name   string
+		locale string
+		key    string
+		args   Args
+		want   string
+	}{
+		{name: "catalog", locale: "uk", key: "secret_keys.secret_not_found", want: "Секрет не знайдено"},
+		{name: "override wins over catalog", locale: "ru", key: "secret_keys.body_invalid", want: "Переопределено"},
+		{name: "empty override ignored", locale: "ru", key: "secret_keys.rotate_failed", want: "Не удалось сменить секрет"},
+		{name: "region falls back to language", locale: "uk-UA", key: "secret_keys.secret_not_found", want: "Секрет не знайдено"},
+		{name: "unknown locale uses fallback locale", locale: "de", key: "secret_keys.secret_not_found", want: "Секрет не найден"},
+		{name: "missing key from fallback locale", locale: "uk", key: "test.fallback", want: "Запасной"},
+		{name: "missing key from base locale", locale: "uk", key: "test.base", args: Args{"name": "en"}, want: "Base en"},
+		{name: "unknown key", locale: "uk", key: "test.unknown", want: "test.unknown"},
+		{name: "plural one", locale: "ru", key: "test.days", args: Args{"count": 21}, want: "21 день"},
+		{name: "plural few", locale: "ru", key: "test.days", args: Args{"count": int64(3)}, want: "3 дня"},
+		{name: "plural many", locale: "ru", key: "test.days", args: Args{"count": 11}, want: "11 дней"},
+		{name: "plural of fallback locale", locale: "en", key: "test.days", args: Args{"count": 2}, want: "2 дня"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := catalog.T(tt.locale, tt.key, tt.args); got != tt.want {
+				t.Errorf("T(%q, %q) = %q, want %q", tt.locale, tt.key, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,36 @@
+package i18n
+
+import "strings"
+
+// Plural forms, named after the CLDR categories
+const (
+	PluralOne   = "one"
+	PluralFew   = "few"
+	PluralMany  = "many"
+	PluralOther = "other"
+)
+
+// PluralForm returns the plural form of n in the locale.
+func PluralForm(locale string, n int) string {
+	if n < 0 {
+		n = -n
+	}
+
+	language, _, _ := strings.Cut(NormalizeLocale(locale), "-")
+	switch language {
+	case "ru", "uk", "be":
+		switch {
+		case n%10 == 1 && n%100 != 11:
+			return PluralOne
+		case n%10 >= 2 && n%10 <= 4 && (n%100 < 12 || n%100 > 14):
+			return PluralFew
+		default:
+			return PluralMany
+		}
+	default:
+		if n == 1 {
+			return PluralOne
+		}
+		return PluralOther
+	}
+}
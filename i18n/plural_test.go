@@ -0,0 +1,38 @@
+package i18n
+
+import "testing"
+
+func TestPluralForm(t *testing.T) {
+	tests := []struct {
+		locale string
+		n      int
+		want   string
+	}{
+		{"en", 0, PluralOther},
+		{"en", 1, PluralOne},
+		{"en", 2, PluralOther},
+		{"en-US", 1, PluralOne},
+		{"ru", 1, PluralOne},
+		{"ru", 2, PluralFew},
+		{"ru", 4, PluralFew},
+		{"ru", 5, PluralMany},
+		{"ru", 11, PluralMany},
+		{"ru", 12, PluralMany},
+		{"ru", 14, PluralMany},
+		{"ru", 21, PluralOne},
+		{"ru", 22, PluralFew},
+		{"ru", 111, PluralMany},
+		{"ru", 0, PluralMany},
+		{"ru", -1, PluralOne},
+		{"uk", 1, PluralOne},
+		{"uk", 3, PluralFew},
+		{"uk", 25, PluralMany},
+		{"uk_UA", 101, PluralOne},
+	}
+
+	for _, tt := range tests {
+		if got := PluralForm(tt.locale, tt.n); got != tt.want {
+			t.Errorf("PluralForm(%q, %d) = %q, want %q", tt.locale, tt.n, got, tt.want)
+		}
+	}
+}
@@ -4,22 +4,14 @@ import (
 	"log"
 	"os"
 	"strings"
-	"time"
 
 	"github.com/pocketbase/pocketbase"
 	"github.com/pocketbase/pocketbase/plugins/migratecmd"
-	"github.com/pocketbase/pocketbase/tools/security"
 
 	"github.com/docker-pet/backend/core"
+	"github.com/docker-pet/backend/i18n"
 	_ "github.com/docker-pet/backend/migrations"
 	"github.com/docker-pet/backend/models"
-	"github.com/docker-pet/backend/modules/app_config"
-	"github.com/docker-pet/backend/modules/lampa"
-	"github.com/docker-pet/backend/modules/otp_auth"
-	"github.com/docker-pet/backend/modules/outline"
-	"github.com/docker-pet/backend/modules/telegram_bot"
-	"github.com/docker-pet/backend/modules/telegram_miniapp"
-	"github.com/docker-pet/backend/modules/users"
 )
 
 var (
@@ -37,62 +29,45 @@ func main() {
 		Automigrate: isGoRun,
 	})
 
-	// HTTP Client
-	httpClient := core.NewHttpClient()
-	defer httpClient.Close()
-
-	// Modules
-	ctx := &core.AppContext{
-		App:        app,
-		HttpClient: httpClient,
+	// Settings
+	settings, err := loadSettings()
+	if err != nil {
+		log.Fatal(err)
 	}
 
-	core.RegisterModule(&app_config.AppConfigModule{}, &app_config.Config{
-		Version: models.AppVersion{
-			Version:   Version,
-			Commit:    Commit,
-			BuildTime: BuildTime,
-		},
-	})
-
-	core.RegisterModule(&users.UsersModule{}, &users.Config{})
-
-	core.RegisterModule(&lampa.LampaModule{}, &lampa.Config{
-		StoragePath: "./generated/lampa",
-	})
-
-	core.RegisterModule(&otp_auth.OtpAuthModule{}, &otp_auth.Config{
-		SessionVerifyInterval:             time.Minute * 7,
-		AuthSessionLifetime:               time.Minute * 5,
-		ExpiredAuthSessionCleanupInterval: time.Minute * 15,
-		MaxPinGenerationAttempts:          10,
-	})
-
-	core.RegisterModule(&telegram_bot.TelegramBotModule{}, &telegram_bot.Config{
-		CronUserSyncExpression: "*/15 * * * *",
-		CronUserSyncInterval:   time.Minute * 60,
-		CronUsersPerSync:       10,
-	})
+	settings.Modules.AppConfig.Config.Version = models.AppVersion{
+		Version:   Version,
+		Commit:    Commit,
+		BuildTime: BuildTime,
+	}
 
-	core.RegisterModule(&telegram_miniapp.TelegramMiniappModule{}, &telegram_miniapp.Config{
-		AuthTokenLifetime: time.Hour * 12,
-	})
+	// HTTP Clients
+	httpClients, err := core.NewHttpClients(settings.httpClientProfiles(), nil)
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer httpClients.Close()
 
-	core.RegisterModule(&outline.OutlineModule{}, &outline.Config{
-		OutlineStoragePath:        "./generated/outline",
-		OutlineCipher:             "chacha20-ietf-poly1305",
-		OutlineTechnicalKeyName:   "service",
-		OutlineTechnicalKeySecret: security.RandomString(32),
+	// Translations
+	catalog, err := i18n.NewCatalog(settings.FallbackLocale)
+	if err != nil {
+		log.Fatal(err)
+	}
 
-		PrometheusStoragePath:       "./generated/prometheus",
-		PrometheusJobName:           "outline",
-		PrometheusJobManagedByLabel: "github.com/docker-pet",
+	// Modules
+	ctx := &core.AppContext{
+		App:             app,
+		HttpClients:     httpClients,
+		I18n:            catalog,
+		ShutdownTimeout: settings.ShutdownTimeout,
+		Metrics:         core.NewMetricsRegistry(),
+		MetricsSecret:   settings.MetricsSecret,
+	}
 
-		CaddyCloudflareApiToken: os.Getenv("CLOUDFLARE_API_TOKEN"),
-	})
+	registerModules(settings)
 
 	// Star app
-	err := core.InitModules(ctx)
+	err = core.InitModules(ctx)
 	if err != nil {
 		log.Fatal(err)
 	} else if err := app.Start(); err != nil {
@@ -0,0 +1,86 @@
+package migrations
+
+import (
+	"github.com/pocketbase/pocketbase/core"
+	m "github.com/pocketbase/pocketbase/migrations"
+	"github.com/pocketbase/pocketbase/tools/types"
+)
+
+func init() {
+	m.Register(func(app core.App) error {
+		// Users collection
+		usersCollection, err := app.FindCollectionByNameOrId("users")
+		if err != nil {
+			return err
+		}
+
+		// Audit log migration
+		collection := core.NewBaseCollection("audit_log")
+
+		// Rules (entries are written by the backend only)
+		collection.ListRule = types.Pointer("@request.auth.role = 'admin'")
+		collection.ViewRule = types.Pointer("@request.auth.role = 'admin'")
+
+		// Fields
+		collection.Fields.Add(
+			&core.TextField{
+				Name:     "module",
+				Required: true,
+				Max:      64,
+			},
+			&core.TextField{
+				Name:     "action",
+				Required: true,
+				Max:      128,
+			},
+			&core.RelationField{
+				Name:         "actor",
+				CollectionId: usersCollection.Id,
+				Required:     false,
+				MaxSelect:    1,
+			},
+			&core.TextField{
+				Name:     "actorLabel",
+				Required: false,
+				Max:      256,
+			},
+			&core.RelationField{
+				Name:         "target",
+				CollectionId: usersCollection.Id,
+				Required:     false,
+				MaxSelect:    1,
+			},
+			&core.JSONField{
+				Name:     "before",
+				Required: false,
+			},
+			&core.JSONField{
+				Name:     "after",
+				Required: false,
+			},
+			&core.TextField{
+				Name:     "ip",
+				Required: false,
+				Max:      64,
+			},
+			&core.AutodateField{
+				Name:     "created",
+				OnCreate: true,
+			},
+		)
+
+		// Indexes
+		collection.AddIndex("idx_audit_log__action", false, "action", "")
+		collection.AddIndex("idx_audit_log__target", false, "target", "")
+		collection.AddIndex("idx_audit_log__created", false, "created", "")
+
+		return app.Save(collection)
+	}, func(app core.App) error {
+		collection, err := app.FindCollectionByNameOrId("audit_log")
+		if err != nil {
+			return err
+		}
+
+		return app.Delete(collection)
+	})
+}
@@ -0,0 +1,82 @@
+package migrations
+
+import (
+	"github.com/pocketbase/pocketbase/core"
+	m "github.com/pocketbase/pocketbase/migrations"
+)
+
+func init() {
+	m.Register(func(app core.App) error {
+		// Users collection
+		usersCollection, err := app.FindCollectionByNameOrId("users")
+		if err != nil {
+			return err
+		}
+
+		// Config revisions migration
+		collection := core.NewBaseCollection("config_revisions")
+
+		// Rules are left empty: snapshots hold secrets, admins use the
+		// config history API which masks them
+
+		// Fields
+		collection.Fields.Add(
+			&core.TextField{
+				Name:     "configCollection", // "collectionName" is a reserved record field
+				Required: true,
+				Max:      64,
+			},
+			&core.TextField{
+				Name:     "recordId",
+				Required: true,
+				Max:      64,
+			},
+			&core.JSONField{
+				Name:     "snapshot",
+				Required: true,
+				Hidden:   true,
+			},
+			&core.JSONField{
+				Name:     "previousSnapshot",
+				Required: false,
+				Hidden:   true,
+			},
+			&core.JSONField{
+				Name:     "changedFields",
+				Required: false,
+			},
+			&core.RelationField{
+				Name:         "author",
+				CollectionId: usersCollection.Id,
+				Required:     false,
+				MaxSelect:    1,
+			},
+			&core.TextField{
+				Name:     "authorLabel",
+				Required: false,
+				Max:      256,
+			},
+			&core.TextField{
+				Name:     "note",
+				Required: false,
+				Max:      256,
+			},
+			&core.AutodateField{
+				Name:     "created",
+				OnCreate: true,
+			},
+		)
+
+		// Indexes
+		collection.AddIndex("idx_config_revisions__record", false, "configCollection, recordId", "")
+
+		return app.Save(collection)
+	}, func(app core.App) error {
+		collection, err := app.FindCollectionByNameOrId("config_revisions")
+		if err != nil {
+			return err
+		}
+
+		return app.Delete(collection)
+	})
+}
@@ -0,0 +1,64 @@
+package migrations
+
+import (
+	"github.com/pocketbase/pocketbase/core"
+	m "github.com/pocketbase/pocketbase/migrations"
+)
+
+func init() {
+	m.Register(func(app core.App) error {
+		// Retired secret keys migration
+		collection := core.NewBaseCollection("secret_keys")
+
+		// Rules are left empty: records hold secrets, admins use the
+		// secret keys API which only returns key ids. Secrets are stored in
+		// plain text as they are needed to verify old signatures, and are
+		// removed by the cleanup cron once expired.
+
+		// Fields
+		collection.Fields.Add(
+			&core.TextField{
+				Name:     "scope",
+				Required: true,
+				Max:      64,
+			},
+			&core.TextField{
+				Name:     "recordId",
+				Required: true,
+				Max:      64,
+			},
+			&core.TextField{
+				Name:     "kid",
+				Required: true,
+				Max:      32,
+			},
+			&core.TextField{
+				Name:     "secret",
+				Required: true,
+				Max:      256,
+				Hidden:   true,
+			},
+			&core.DateField{
+				Name:     "expires",
+				Required: true,
+			},
+			&core.AutodateField{
+				Name:     "created",
+				OnCreate: true,
+			},
+		)
+
+		// Indexes
+		collection.AddIndex("idx_secret_keys__record", false, "scope, recordId", "")
+		collection.AddIndex("idx_secret_keys__expires", false, "expires", "")
+
+		return app.Save(collection)
+	}, func(app core.App) error {
+		collection, err := app.FindCollectionByNameOrId("secret_keys")
+		if err != nil {
+			return err
+		}
+
+		return app.Delete(collection)
+	})
+}
@@ -0,0 +1,64 @@
+package migrations
+
+import (
+	"github.com/pocketbase/pocketbase/core"
+	m "github.com/pocketbase/pocketbase/migrations"
+	"github.com/pocketbase/pocketbase/tools/types"
+)
+
+func init() {
+	m.Register(func(app core.App) error {
+		// Translation overrides migration
+		collection := core.NewBaseCollection("translations")
+
+		// Rules
+		collection.ListRule = types.Pointer("@request.auth.role = 'admin'")
+		collection.ViewRule = types.Pointer("@request.auth.role = 'admin'")
+		collection.ManageRule = types.Pointer("@request.auth.role = 'admin'")
+
+		// Fields
+		collection.Fields.Add(
+			&core.TextField{
+				Name:     "locale",
+				Required: true,
+				Max:      16,
+				Pattern:  `^[a-z]{2,3}(-[a-z0-9]+)*$`,
+			},
+			&core.TextField{
+				Name:     "key",
+				Required: true,
+				Max:      128,
+			},
+			&core.TextField{
+				Name:     "value",
+				Required: true,
+				Max:      4096,
+			},
+			&core.JSONField{
+				Name:     "plurals",
+				Required: false,
+			},
+			&core.AutodateField{
+				Name:     "created",
+				OnCreate: true,
+			},
+			&core.AutodateField{
+				Name:     "updated",
+				OnCreate: true,
+				OnUpdate: true,
+			},
+		)
+
+		// Indexes
+		collection.AddIndex("idx_translations__locale_key", true, "locale, `key`", "")
+
+		return app.Save(collection)
+	}, func(app core.App) error {
+		collection, err := app.FindCollectionByNameOrId("translations")
+		if err != nil {
+			return err
+		}
+
+		return app.Delete(collection)
+	})
+}
@@ -0,0 +1,70 @@
+package migrations
+
+import (
+	"github.com/pocketbase/pocketbase/core"
+	m "github.com/pocketbase/pocketbase/migrations"
+)
+
+func init() {
+	m.Register(func(app core.App) error {
+		// Users collection
+		collection, err := app.FindCollectionByNameOrId("users")
+		if err != nil {
+			return err
+		}
+
+		// Membership derived from the Telegram channels is kept apart from
+		// the admin overrides, role and premium hold the effective values
+		collection.Fields.Add(
+			&core.SelectField{
+				Name:     "memberRole",
+				Required: false,
+				Values:   []string{"guest", "user", "admin"},
+			},
+			&core.BoolField{
+				Name:     "memberPremium",
+				Required: false,
+			},
+			&core.BoolField{
+				Name:     "banned",
+				Required: false,
+			},
+			&core.TextField{
+				Name:     "banReason",
+				Required: false,
+				Max:      512,
+			},
+			&core.SelectField{
+				Name:     "roleOverride",
+				Required: false,
+				Values:   []string{"guest", "user", "admin"},
+			},
+			&core.DateField{
+				Name:     "premiumUntil",
+				Required: false,
+			},
+		)
+
+		if err := app.Save(collection); err != nil {
+			return err
+		}
+
+		// Current values were derived from the channels
+		_, err = app.DB().NewQuery("UPDATE users SET memberRole = role, memberPremium = premium").Execute()
+		return err
+	}, func(app core.App) error {
+		collection, err := app.FindCollectionByNameOrId("users")
+		if err != nil {
+			return err
+		}
+
+		collection.Fields.RemoveByName("memberRole")
+		collection.Fields.RemoveByName("memberPremium")
+		collection.Fields.RemoveByName("banned")
+		collection.Fields.RemoveByName("banReason")
+		collection.Fields.RemoveByName("roleOverride")
+		collection.Fields.RemoveByName("premiumUntil")
+
+		return app.Save(collection)
+	})
+}
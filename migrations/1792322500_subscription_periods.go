@@ -0,0 +1,113 @@
+package migrations
+
+import (
+	"github.com/pocketbase/pocketbase/core"
+	m "github.com/pocketbase/pocketbase/migrations"
+	"github.com/pocketbase/pocketbase/tools/types"
+)
+
+func init() {
+	m.Register(func(app core.App) error {
+		// Users collection
+		usersCollection, err := app.FindCollectionByNameOrId("users")
+		if err != nil {
+			return err
+		}
+
+		// End of the current access and premium periods, kept up to date by
+		// the subscriptions module
+		usersCollection.Fields.Add(
+			&core.DateField{
+				Name:     "accessPeriodEnds",
+				Required: false,
+			},
+			&core.DateField{
+				Name:     "premiumPeriodEnds",
+				Required: false,
+			},
+		)
+
+		if err := app.Save(usersCollection); err != nil {
+			return err
+		}
+
+		// Subscription periods migration
+		collection := core.NewBaseCollection("subscription_periods")
+
+		// Rules, users can see their own periods, admins manage them through
+		// the admin endpoints
+		collection.ListRule = types.Pointer("user = @request.auth.id")
+		collection.ViewRule = types.Pointer("user = @request.auth.id")
+
+		// Fields
+		collection.Fields.Add(
+			&core.RelationField{
+				Name:          "user",
+				CollectionId:  usersCollection.Id,
+				Required:      true,
+				CascadeDelete: true,
+				MinSelect:     1,
+				MaxSelect:     1,
+			},
+			&core.SelectField{
+				Name:      "kind",
+				Required:  true,
+				MaxSelect: 1,
+				Values:    []string{"access", "premium"},
+			},
+			&core.DateField{
+				Name:     "starts",
+				Required: true,
+			},
+			&core.DateField{
+				Name:     "ends",
+				Required: true,
+			},
+			&core.TextField{
+				Name:     "note",
+				Required: false,
+				Max:      512,
+			},
+			&core.BoolField{
+				Name: "notified",
+			},
+			&core.BoolField{
+				Name: "expired",
+			},
+			&core.AutodateField{
+				Name:     "created",
+				OnCreate: true,
+			},
+			&core.AutodateField{
+				Name:     "updated",
+				OnCreate: true,
+				OnUpdate: true,
+			},
+		)
+
+		// Indexes
+		collection.AddIndex("idx_subscription_periods__user", false, "user, kind", "")
+		collection.AddIndex("idx_subscription_periods__ends", false, "expired, ends", "")
+
+		return app.Save(collection)
+	}, func(app core.App) error {
+		collection, err := app.FindCollectionByNameOrId("subscription_periods")
+		if err != nil {
+			return err
+		}
+
+		if err := app.Delete(collection); err != nil {
+			return err
+		}
+
+		usersCollection, err := app.FindCollectionByNameOrId("users")
+		if err != nil {
+			return err
+		}
+
+		usersCollection.Fields.RemoveByName("accessPeriodEnds")
+		usersCollection.Fields.RemoveByName("premiumPeriodEnds")
+
+		return app.Save(usersCollection)
+	})
+}
@@ -0,0 +1,72 @@
+package migrations
+
+import (
+	"github.com/pocketbase/pocketbase/core"
+	m "github.com/pocketbase/pocketbase/migrations"
+)
+
+func init() {
+	m.Register(func(app core.App) error {
+		// Users collection
+		collection, err := app.FindCollectionByNameOrId("users")
+		if err != nil {
+			return err
+		}
+
+		// Avatars are hashed by content with sha256
+		if field, ok := collection.Fields.GetByName("avatarHash").(*core.TextField); ok {
+			field.Max = 64
+		}
+
+		// Avatars are stored as square JPEGs, SVG is no longer accepted
+		if field, ok := collection.Fields.GetByName("avatar").(*core.FileField); ok {
+			field.Thumbs = []string{"64x64", "128x128", "250x250"}
+			field.MaxSize = 5 << 20
+			field.MimeTypes = []string{
+				"image/jpeg",
+				"image/png",
+				"image/gif",
+				"image/webp",
+			}
+		}
+
+		if err := app.Save(collection); err != nil {
+			return err
+		}
+
+		// URL hashes never match a content hash, the next login refreshes
+		// every avatar
+		_, err = app.DB().NewQuery("UPDATE users SET avatarHash = ''").Execute()
+		return err
+	}, func(app core.App) error {
+		collection, err := app.FindCollectionByNameOrId("users")
+		if err != nil {
+			return err
+		}
+
+		if field, ok := collection.Fields.GetByName("avatarHash").(*core.TextField); ok {
+			field.Max = 32
+		}
+
+		if field, ok := collection.Fields.GetByName("avatar").(*core.FileField); ok {
+			field.Thumbs = []string{"250x250"}
+			field.MaxSize = 0
+			field.MimeTypes = []string{
+				"image/jpeg",
+				"image/png",
+				"image/gif",
+				"image/webp",
+				"image/svg+xml",
+				"image/avif",
+				"image/apng",
+			}
+		}
+
+		if err := app.Save(collection); err != nil {
+			return err
+		}
+
+		_, err = app.DB().NewQuery("UPDATE users SET avatarHash = ''").Execute()
+		return err
+	})
+}
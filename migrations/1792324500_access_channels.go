@@ -0,0 +1,172 @@
+package migrations
+
+import (
+	"strconv"
+
+	"github.com/pocketbase/dbx"
+	"github.com/pocketbase/pocketbase/core"
+	m "github.com/pocketbase/pocketbase/migrations"
+	"github.com/pocketbase/pocketbase/tools/types"
+)
+
+func init() {
+	m.Register(func(app core.App) error {
+		// Access channels migration
+		collection := core.NewBaseCollection("access_channels")
+
+		// Rules
+		collection.ListRule = types.Pointer("@request.auth.role = 'admin'")
+		collection.ViewRule = types.Pointer("@request.auth.role = 'admin'")
+		collection.ManageRule = types.Pointer("@request.auth.role = 'admin'")
+
+		// Fields
+		collection.Fields.Add(
+			&core.NumberField{
+				Name:     "chatId",
+				Required: true,
+				OnlyInt:  true,
+			},
+			&core.TextField{
+				Name:     "title",
+				Required: false,
+				Max:      128,
+			},
+			&core.SelectField{
+				Name:      "grant",
+				Required:  true,
+				MaxSelect: 1,
+				Values:    []string{"user", "admin", "premium"},
+			},
+			&core.NumberField{
+				Name:     "priority",
+				Required: false,
+				OnlyInt:  true,
+			},
+			&core.BoolField{
+				Name: "promoteAdmins",
+			},
+			&core.BoolField{
+				Name: "enabled",
+			},
+			&core.AutodateField{
+				Name:     "created",
+				OnCreate: true,
+			},
+			&core.AutodateField{
+				Name:     "updated",
+				OnCreate: true,
+				OnUpdate: true,
+			},
+		)
+
+		// Indexes
+		collection.AddIndex("idx_access_channels__chat_id", true, "chatId", "")
+
+		if err := app.Save(collection); err != nil {
+			return err
+		}
+
+		// Chat member statuses of the user by chat id
+		usersCollection, err := app.FindCollectionByNameOrId("users")
+		if err != nil {
+			return err
+		}
+
+		usersCollection.Fields.Add(&core.JSONField{
+			Name:     "memberships",
+			Required: false,
+			Hidden:   true,
+		})
+
+		if err := app.Save(usersCollection); err != nil {
+			return err
+		}
+
+		// The channels of the app config become the first access channels
+		appConfigs, err := app.FindAllRecords("app")
+		if err != nil || len(appConfigs) == 0 {
+			return err
+		}
+
+		// 0 and the -1 placeholder are not configured
+		isChannel := func(chatId int) bool { return chatId != 0 && chatId != -1 }
+		mainChannelId := appConfigs[0].GetInt("telegramChannelId")
+		premiumChannelId := appConfigs[0].GetInt("telegramPremiumChannelId")
+
+		channels := []struct {
+			chatId        int
+			title         string
+			grant         string
+			priority      int
+			promoteAdmins bool
+		}{
+			{mainChannelId, "Main channel", "user", 10, true},
+			{premiumChannelId, "Premium channel", "premium", 0, false},
+		}
+		for _, channel := range channels {
+			if !isChannel(channel.chatId) {
+				continue
+			}
+
+			record := core.NewRecord(collection)
+			record.Set("chatId", channel.chatId)
+			record.Set("title", channel.title)
+			record.Set("grant", channel.grant)
+			record.Set("priority", channel.priority)
+			record.Set("promoteAdmins", channel.promoteAdmins)
+			record.Set("enabled", true)
+			if err := app.Save(record); err != nil {
+				return err
+			}
+		}
+
+		// Memberships follow the current membership role and premium
+		users, err := app.FindAllRecords("users", dbx.Or(
+			dbx.In("memberRole", "user", "admin"),
+			dbx.HashExp{"memberPremium": true},
+		))
+		if err != nil {
+			return err
+		}
+
+		for _, user := range users {
+			memberships := map[string]string{}
+			if isChannel(mainChannelId) {
+				switch user.GetString("memberRole") {
+				case "admin":
+					memberships[strconv.Itoa(mainChannelId)] = "administrator"
+				case "user":
+					memberships[strconv.Itoa(mainChannelId)] = "member"
+				}
+			}
+			if user.GetBool("memberPremium") && isChannel(premiumChannelId) {
+				memberships[strconv.Itoa(premiumChannelId)] = "member"
+			}
+
+			user.Set("memberships", memberships)
+			if err := app.SaveNoValidate(user); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	}, func(app core.App) error {
+		collection, err := app.FindCollectionByNameOrId("access_channels")
+		if err != nil {
+			return err
+		}
+
+		if err := app.Delete(collection); err != nil {
+			return err
+		}
+
+		usersCollection, err := app.FindCollectionByNameOrId("users")
+		if err != nil {
+			return err
+		}
+
+		usersCollection.Fields.RemoveByName("memberships")
+
+		return app.Save(usersCollection)
+	})
+}
@@ -0,0 +1,72 @@
+package models
+
+import (
+	"github.com/pocketbase/pocketbase/core"
+)
+
+var _ core.RecordProxy = (*AccessChannel)(nil)
+
+type AccessGrant string
+
+const (
+	AccessGrantUser    AccessGrant = "user"
+	AccessGrantAdmin   AccessGrant = "admin"
+	AccessGrantPremium AccessGrant = "premium"
+)
+
+// AccessChannel is a Telegram chat whose members get a role or premium.
+type AccessChannel struct {
+	core.BaseRecordProxy
+}
+
+func (a *AccessChannel) ChatId() int64 {
+	return int64(a.GetInt("chatId"))
+}
+
+func (a *AccessChannel) SetChatId(value int64) {
+	a.Set("chatId", value)
+}
+
+func (a *AccessChannel) Title() string {
+	return a.GetString("title")
+}
+
+func (a *AccessChannel) SetTitle(value string) {
+	a.Set("title", value)
+}
+
+// Grant is the role or the entitlement given to the members.
+func (a *AccessChannel) Grant() AccessGrant {
+	return AccessGrant(a.GetString("grant"))
+}
+
+func (a *AccessChannel) SetGrant(value AccessGrant) {
+	a.Set("grant", string(value))
+}
+
+// Priority orders the channels, the role comes from the member's channel
+// with the highest priority.
+func (a *AccessChannel) Priority() int {
+	return a.GetInt("priority")
+}
+
+func (a *AccessChannel) SetPriority(value int) {
+	a.Set("priority", value)
+}
+
+// PromoteAdmins makes the administrators of the chat app admins.
+func (a *AccessChannel) PromoteAdmins() bool {
+	return a.GetBool("promoteAdmins")
+}
+
+func (a *AccessChannel) SetPromoteAdmins(value bool) {
+	a.Set("promoteAdmins", value)
+}
+
+func (a *AccessChannel) Enabled() bool {
+	return a.GetBool("enabled")
+}
+
+func (a *AccessChannel) SetEnabled(value bool) {
+	a.Set("enabled", value)
+}
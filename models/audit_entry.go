@@ -0,0 +1,72 @@
+package models
+
+import (
+	"github.com/pocketbase/pocketbase/core"
+	"github.com/pocketbase/pocketbase/tools/types"
+)
+
+var _ core.RecordProxy = (*AuditEntry)(nil)
+
+type AuditEntry struct {
+	core.BaseRecordProxy
+}
+
+func (a *AuditEntry) Module() string {
+	return a.GetString("module")
+}
+
+func (a *AuditEntry) SetModule(value string) {
+	a.Set("module", value)
+}
+
+func (a *AuditEntry) Action() string {
+	return a.GetString("action")
+}
+
+func (a *AuditEntry) SetAction(value string) {
+	a.Set("action", value)
+}
+
+func (a *AuditEntry) ActorId() string {
+	return a.GetString("actor")
+}
+
+func (a *AuditEntry) SetActorId(value string) {
+	a.Set("actor", value)
+}
+
+func (a *AuditEntry) ActorLabel() string {
+	return a.GetString("actorLabel")
+}
+
+func (a *AuditEntry) SetActorLabel(value string) {
+	a.Set("actorLabel", value)
+}
+
+func (a *AuditEntry) TargetId() string {
+	return a.GetString("target")
+}
+
+func (a *AuditEntry) SetTargetId(value string) {
+	a.Set("target", value)
+}
+
+func (a *AuditEntry) SetBefore(value any) {
+	a.Set("before", value)
+}
+
+func (a *AuditEntry) SetAfter(value any) {
+	a.Set("after", value)
+}
+
+func (a *AuditEntry) IP() string {
+	return a.GetString("ip")
+}
+
+func (a *AuditEntry) SetIP(value string) {
+	a.Set("ip", value)
+}
+
+func (a *AuditEntry) Created() types.DateTime {
+	return a.GetDateTime("created")
+}
@@ -0,0 +1,86 @@
+package models
+
+import (
+	"github.com/pocketbase/pocketbase/core"
+	"github.com/pocketbase/pocketbase/tools/types"
+)
+
+var _ core.RecordProxy = (*ConfigRevision)(nil)
+
+type ConfigRevision struct {
+	core.BaseRecordProxy
+}
+
+func (a *ConfigRevision) CollectionName() string {
+	return a.GetString("configCollection")
+}
+
+func (a *ConfigRevision) SetCollectionName(value string) {
+	a.Set("configCollection", value)
+}
+
+func (a *ConfigRevision) RecordId() string {
+	return a.GetString("recordId")
+}
+
+func (a *ConfigRevision) SetRecordId(value string) {
+	a.Set("recordId", value)
+}
+
+func (a *ConfigRevision) Snapshot() map[string]any {
+	snapshot := map[string]any{}
+	a.UnmarshalJSONField("snapshot", &snapshot)
+	return snapshot
+}
+
+func (a *ConfigRevision) SetSnapshot(value map[string]any) {
+	a.Set("snapshot", value)
+}
+
+func (a *ConfigRevision) PreviousSnapshot() map[string]any {
+	snapshot := map[string]any{}
+	a.UnmarshalJSONField("previousSnapshot", &snapshot)
+	return snapshot
+}
+
+func (a *ConfigRevision) SetPreviousSnapshot(value map[string]any) {
+	a.Set("previousSnapshot", value)
+}
+
+func (a *ConfigRevision) ChangedFields() []string {
+	var fields []string
+	a.UnmarshalJSONField("changedFields", &fields)
+	return fields
+}
+
+func (a *ConfigRevision) SetChangedFields(value []string) {
+	a.Set("changedFields", value)
+}
+
+func (a *ConfigRevision) AuthorId() string {
+	return a.GetString("author")
+}
+
+func (a *ConfigRevision) SetAuthorId(value string) {
+	a.Set("author", value)
+}
+
+func (a *ConfigRevision) AuthorLabel() string {
+	return a.GetString("authorLabel")
+}
+
+func (a *ConfigRevision) SetAuthorLabel(value string) {
+	a.Set("authorLabel", value)
+}
+
+func (a *ConfigRevision) Note() string {
+	return a.GetString("note")
+}
+
+func (a *ConfigRevision) SetNote(value string) {
+	a.Set("note", value)
+}
+
+func (a *ConfigRevision) Created() types.DateTime {
+	return a.GetDateTime("created")
+}
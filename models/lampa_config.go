@@ -3,6 +3,7 @@ package models
 import (
 	"github.com/Jeffail/gabs/v2"
 	"github.com/pocketbase/pocketbase/core"
+	"github.com/pocketbase/pocketbase/tools/security"
 )
 
 var _ core.RecordProxy = (*LampaConfig)(nil)
@@ -83,6 +84,10 @@ func (a *LampaConfig) SetAdminPassword(value string) {
 	a.Set("adminPassword", value)
 }
 
+func (a *LampaConfig) GenerateAdminPassword() {
+	a.Set("adminPassword", security.RandomString(30))
+}
+
 func (a *LampaConfig) ConfigInit() *gabs.Container {
 	value := a.GetString("configInit")
 	if value == "" {
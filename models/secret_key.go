@@ -0,0 +1,57 @@
+package models
+
+import (
+	"github.com/pocketbase/pocketbase/core"
+	"github.com/pocketbase/pocketbase/tools/types"
+)
+
+var _ core.RecordProxy = (*SecretKey)(nil)
+
+// SecretKey is a retired secret still accepted until it expires.
+type SecretKey struct {
+	core.BaseRecordProxy
+}
+
+func (a *SecretKey) Scope() string {
+	return a.GetString("scope")
+}
+
+func (a *SecretKey) SetScope(value string) {
+	a.Set("scope", value)
+}
+
+func (a *SecretKey) RecordId() string {
+	return a.GetString("recordId")
+}
+
+func (a *SecretKey) SetRecordId(value string) {
+	a.Set("recordId", value)
+}
+
+func (a *SecretKey) Kid() string {
+	return a.GetString("kid")
+}
+
+func (a *SecretKey) SetKid(value string) {
+	a.Set("kid", value)
+}
+
+func (a *SecretKey) Secret() string {
+	return a.GetString("secret")
+}
+
+func (a *SecretKey) SetSecret(value string) {
+	a.Set("secret", value)
+}
+
+func (a *SecretKey) Expires() types.DateTime {
+	return a.GetDateTime("expires")
+}
+
+func (a *SecretKey) SetExpires(value types.DateTime) {
+	a.Set("expires", value)
+}
+
+func (a *SecretKey) Created() types.DateTime {
+	return a.GetDateTime("created")
+}
@@ -0,0 +1,86 @@
+package models
+
+import (
+	"time"
+
+	"github.com/pocketbase/pocketbase/core"
+	"github.com/pocketbase/pocketbase/tools/types"
+)
+
+var _ core.RecordProxy = (*SubscriptionPeriod)(nil)
+
+type SubscriptionKind string
+
+const (
+	SubscriptionAccess  SubscriptionKind = "access"
+	SubscriptionPremium SubscriptionKind = "premium"
+)
+
+// SubscriptionPeriod grants access or premium to a user between starts
+// and ends.
+type SubscriptionPeriod struct {
+	core.BaseRecordProxy
+}
+
+func (a *SubscriptionPeriod) UserId() string {
+	return a.GetString("user")
+}
+
+func (a *SubscriptionPeriod) SetUserId(id string) {
+	a.Set("user", id)
+}
+
+func (a *SubscriptionPeriod) Kind() SubscriptionKind {
+	return SubscriptionKind(a.GetString("kind"))
+}
+
+func (a *SubscriptionPeriod) SetKind(kind SubscriptionKind) {
+	a.Set("kind", string(kind))
+}
+
+func (a *SubscriptionPeriod) Starts() types.DateTime {
+	return a.GetDateTime("starts")
+}
+
+func (a *SubscriptionPeriod) SetStarts(date types.DateTime) {
+	a.Set("starts", date)
+}
+
+func (a *SubscriptionPeriod) Ends() types.DateTime {
+	return a.GetDateTime("ends")
+}
+
+func (a *SubscriptionPeriod) SetEnds(date types.DateTime) {
+	a.Set("ends", date)
+}
+
+func (a *SubscriptionPeriod) Note() string {
+	return a.GetString("note")
+}
+
+func (a *SubscriptionPeriod) SetNote(note string) {
+	a.Set("note", note)
+}
+
+// Notified is set once the user was warned about the upcoming expiry.
+func (a *SubscriptionPeriod) Notified() bool {
+	return a.GetBool("notified")
+}
+
+func (a *SubscriptionPeriod) SetNotified(notified bool) {
+	a.Set("notified", notified)
+}
+
+// Expired is set once the expiry of the period was processed.
+func (a *SubscriptionPeriod) Expired() bool {
+	return a.GetBool("expired")
+}
+
+func (a *SubscriptionPeriod) SetExpired(expired bool) {
+	a.Set("expired", expired)
+}
+
+// ActiveAt reports whether the period covers the moment.
+func (a *SubscriptionPeriod) ActiveAt(now time.Time) bool {
+	return !a.Starts().Time().After(now) && a.Ends().Time().After(now)
+}
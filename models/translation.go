@@ -0,0 +1,48 @@
+package models
+
+import (
+	"github.com/pocketbase/pocketbase/core"
+)
+
+var _ core.RecordProxy = (*Translation)(nil)
+
+// Translation overrides a message of the embedded catalogs.
+type Translation struct {
+	core.BaseRecordProxy
+}
+
+func (a *Translation) Locale() string {
+	return a.GetString("locale")
+}
+
+func (a *Translation) SetLocale(value string) {
+	a.Set("locale", value)
+}
+
+func (a *Translation) Key() string {
+	return a.GetString("key")
+}
+
+func (a *Translation) SetKey(value string) {
+	a.Set("key", value)
+}
+
+// Value is the text, or the "other" plural form.
+func (a *Translation) Value() string {
+	return a.GetString("value")
+}
+
+func (a *Translation) SetValue(value string) {
+	a.Set("value", value)
+}
+
+// Plurals holds the other plural forms, e.g. {"one": "...", "few": "..."}.
+func (a *Translation) Plurals() map[string]string {
+	plurals := map[string]string{}
+	a.UnmarshalJSONField("plurals", &plurals)
+	return plurals
+}
+
+func (a *Translation) SetPlurals(value map[string]string) {
+	a.Set("plurals", value)
+}
@@ -2,7 +2,9 @@ package models
 
 import (
 	"fmt"
+	"strconv"
 	"strings"
+	"time"
 
 	"github.com/pocketbase/pocketbase/core"
 	"github.com/pocketbase/pocketbase/tools/filesystem"
@@ -51,16 +53,12 @@ func (a *User) SetLanguage(language string) {
 	a.Set("language", language)
 }
 
+// Role is the effective role, see ApplyAccess.
 func (a *User) Role() UserRole {
-	role := UserRole(a.GetString("role"))
-	switch role {
-	case RoleUser, RoleAdmin, RoleGuest:
-		return role
-	default:
-		return RoleGuest
-	}
+	return parseRole(a.GetString("role"))
 }
 
+// SetRole sets the effective role, it is recomputed by ApplyAccess on save.
 func (a *User) SetRole(role UserRole) {
 	a.Set("role", string(role))
 
@@ -73,14 +71,197 @@ func (a *User) IsActive() bool {
 	return a.Role() != RoleGuest
 }
 
+// Premium is the effective premium flag, see ApplyAccess.
 func (a *User) Premium() bool {
 	return a.GetBool("premium")
 }
 
+// SetPremium sets the effective premium flag, it is recomputed by
+// ApplyAccess on save.
 func (a *User) SetPremium(premium bool) {
 	a.Set("premium", premium)
 }
 
+// MemberRole is the role derived from the main Telegram channel membership.
+func (a *User) MemberRole() UserRole {
+	return parseRole(a.GetString("memberRole"))
+}
+
+func (a *User) SetMemberRole(role UserRole) {
+	a.Set("memberRole", string(role))
+}
+
+// MemberPremium is derived from the premium Telegram channel membership.
+func (a *User) MemberPremium() bool {
+	return a.GetBool("memberPremium")
+}
+
+func (a *User) SetMemberPremium(premium bool) {
+	a.Set("memberPremium", premium)
+}
+
+// Banned users are guests whatever their membership and overrides are.
+func (a *User) Banned() bool {
+	return a.GetBool("banned")
+}
+
+func (a *User) BanReason() string {
+	return a.GetString("banReason")
+}
+
+func (a *User) SetBanned(banned bool, reason string) {
+	a.Set("banned", banned)
+	if !banned {
+		reason = ""
+	}
+	a.Set("banReason", reason)
+}
+
+// RoleOverride is the role forced by an admin, empty when not overridden.
+func (a *User) RoleOverride() UserRole {
+	value := a.GetString("roleOverride")
+	if value == "" {
+		return ""
+	}
+	return parseRole(value)
+}
+
+func (a *User) SetRoleOverride(role UserRole) {
+	a.Set("roleOverride", string(role))
+}
+
+// PremiumUntil is the expiry of a premium grant made by an admin.
+func (a *User) PremiumUntil() types.DateTime {
+	return a.GetDateTime("premiumUntil")
+}
+
+func (a *User) SetPremiumUntil(date types.DateTime) {
+	a.Set("premiumUntil", date)
+}
+
+// HasPremiumGrant reports whether an admin premium grant is active at now.
+func (a *User) HasPremiumGrant(now time.Time) bool {
+	until := a.PremiumUntil()
+	return !until.IsZero() && until.Time().After(now)
+}
+
+// ExpirePremiumGrant clears a premium grant that has ended at now and
+// applies the access again, a premium period or membership keeps premium.
+// Returns false when there is no ended grant.
+func (a *User) ExpirePremiumGrant(now time.Time) bool {
+	until := a.PremiumUntil()
+	if until.IsZero() || until.Time().After(now) {
+		return false
+	}
+
+	a.SetPremiumUntil(types.DateTime{})
+	a.ApplyAccess(now)
+	return true
+}
+
+// AccessPeriodEnds is the end of the current access subscription period,
+// empty when there is none.
+func (a *User) AccessPeriodEnds() types.DateTime {
+	return a.GetDateTime("accessPeriodEnds")
+}
+
+func (a *User) SetAccessPeriodEnds(date types.DateTime) {
+	a.Set("accessPeriodEnds", date)
+}
+
+// PremiumPeriodEnds is the end of the current premium subscription period,
+// empty when there is none.
+func (a *User) PremiumPeriodEnds() types.DateTime {
+	return a.GetDateTime("premiumPeriodEnds")
+}
+
+func (a *User) SetPremiumPeriodEnds(date types.DateTime) {
+	a.Set("premiumPeriodEnds", date)
+}
+
+func (a *User) HasAccessPeriod(now time.Time) bool {
+	ends := a.AccessPeriodEnds()
+	return !ends.IsZero() && ends.Time().After(now)
+}
+
+func (a *User) HasPremiumPeriod(now time.Time) bool {
+	ends := a.PremiumPeriodEnds()
+	return !ends.IsZero() && ends.Time().After(now)
+}
+
+// EffectiveRole combines the membership with the admin overrides: a ban
+// makes the user a guest, then a role override wins over the membership.
+// A guest with an access period is a user.
+func (a *User) EffectiveRole(now time.Time) UserRole {
+	if a.Banned() {
+		return RoleGuest
+	}
+	if override := a.RoleOverride(); override != "" {
+		return override
+	}
+	if role := a.MemberRole(); role != RoleGuest || !a.HasAccessPeriod(now) {
+		return role
+	}
+	return RoleUser
+}
+
+// EffectivePremium is true for a premium channel member, an active grant or
+// an active premium period, never for a banned user.
+func (a *User) EffectivePremium(now time.Time) bool {
+	if a.Banned() {
+		return false
+	}
+	return a.MemberPremium() || a.HasPremiumGrant(now) || a.HasPremiumPeriod(now)
+}
+
+// AccessExpires is the end of the access of an active user, empty when it
+// doesn't expire (channel membership or role override).
+func (a *User) AccessExpires(now time.Time) types.DateTime {
+	if a.RoleOverride() != "" || a.MemberRole() != RoleGuest || !a.HasAccessPeriod(now) {
+		return types.DateTime{}
+	}
+	return a.AccessPeriodEnds()
+}
+
+// ApplyAccess stores the effective role and premium flag, so collection
+// rules and other modules can rely on role and premium.
+func (a *User) ApplyAccess(now time.Time) {
+	if role := a.EffectiveRole(now); a.GetString("role") != string(role) {
+		a.SetRole(role)
+	}
+	if premium := a.EffectivePremium(now); a.Premium() != premium {
+		a.SetPremium(premium)
+	}
+}
+
+func parseRole(value string) UserRole {
+	role := UserRole(value)
+	switch role {
+	case RoleUser, RoleAdmin, RoleGuest:
+		return role
+	default:
+		return RoleGuest
+	}
+}
+
+// Memberships are the Telegram chat member statuses of the user by chat id,
+// for the access channels.
+func (a *User) Memberships() map[string]string {
+	memberships := map[string]string{}
+	a.UnmarshalJSONField("memberships", &memberships)
+	return memberships
+}
+
+func (a *User) SetMembership(chatId int64, status string) {
+	memberships := a.Memberships()
+	memberships[strconv.FormatInt(chatId, 10)] = status
+	a.Set("memberships", memberships)
+}
+
+func (a *User) Membership(chatId int64) string {
+	return a.Memberships()[strconv.FormatInt(chatId, 10)]
+}
+
 func (a *User) JoinPending() bool {
 	return a.GetBool("joinPending")
 }
@@ -0,0 +1,76 @@
+package models
+
+import (
+	"testing"
+	"time"
+
+	"github.com/pocketbase/pocketbase/core"
+	"github.com/pocketbase/pocketbase/tools/types"
+)
+
+func newTestUser() *User {
+	collection := core.NewAuthCollection("users")
+	collection.Fields.Add(
+		&core.TextField{Name: "role"},
+		&core.TextField{Name: "roleOverride"},
+		&core.TextField{Name: "memberRole"},
+		&core.BoolField{Name: "memberPremium"},
+		&core.BoolField{Name: "premium"},
+		&core.BoolField{Name: "banned"},
+		&core.DateField{Name: "premiumUntil"},
+		&core.DateField{Name: "premiumPeriodEnds"},
+	)
+
+	user := &User{}
+	user.SetProxyRecord(core.NewRecord(collection))
+	return user
+}
+
+func TestUserExpirePremiumGrant(t *testing.T) {
+	now := time.Date(2026, 10, 18, 12, 0, 0, 0, time.UTC)
+	date := func(offset time.Duration) types.DateTime {
+		value, _ := types.ParseDateTime(now.Add(offset))
+		return value
+	}
+
+	tests := []struct {
+		name          string
+		premiumUntil  types.DateTime
+		periodEnds    types.DateTime
+		memberPremium bool
+		wantExpired   bool
+		wantPremium   bool
+	}{
+		{name: "no grant"},
+		{name: "active grant", premiumUntil: date(time.Hour), wantPremium: true},
+		{name: "expired grant", premiumUntil: date(-time.Hour), wantExpired: true},
+		{name: "expired grant with active period", premiumUntil: date(-time.Hour), periodEnds: date(time.Hour), wantExpired: true, wantPremium: true},
+		{name: "expired grant with ended period", premiumUntil: date(-time.Hour), periodEnds: date(-time.Minute), wantExpired: true},
+		{name: "expired grant of premium member", premiumUntil: date(-time.Hour), memberPremium: true, wantExpired: true, wantPremium: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			user := newTestUser()
+			user.SetPremiumUntil(tt.premiumUntil)
+			user.SetPremiumPeriodEnds(tt.periodEnds)
+			user.Set("memberPremium", tt.memberPremium)
+			user.ApplyAccess(now.Add(-2 * time.Hour))
+
+			if got := user.ExpirePremiumGrant(now); got != tt.wantExpired {
+				t.Errorf("ExpirePremiumGrant() = %v, want %v", got, tt.wantExpired)
+			}
+			if got := user.Premium(); got != tt.wantPremium {
+				t.Errorf("Premium() = %v, want %v", got, tt.wantPremium)
+			}
+			if tt.wantExpired && !user.PremiumUntil().IsZero() {
+				t.Errorf("PremiumUntil() = %v, want it cleared", user.PremiumUntil())
+			}
+
+			// The expiry cron selects the user again only for a grant left behind
+			if user.ExpirePremiumGrant(now) {
+				t.Error("ExpirePremiumGrant() = true on the second run, want false")
+			}
+		})
+	}
+}
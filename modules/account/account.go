@@ -0,0 +1,56 @@
+package account
+
+import (
+	"errors"
+	"log/slog"
+	"time"
+
+	"github.com/docker-pet/backend/core"
+	"github.com/docker-pet/backend/modules/lampa"
+	"github.com/docker-pet/backend/modules/subscriptions"
+	"github.com/docker-pet/backend/modules/users"
+)
+
+type Config struct {
+	DeleteConfirmationLifetime time.Duration `yaml:"deleteConfirmationLifetime"` // How long the account deletion confirmation token is valid
+}
+
+func (c *Config) Validate() error {
+	var errs []error
+	if c.DeleteConfirmationLifetime <= 0 {
+		errs = append(errs, errors.New("deleteConfirmationLifetime must be positive"))
+	}
+	return errors.Join(errs...)
+}
+
+// AccountModule lets users export and delete the data stored about them.
+type AccountModule struct {
+	Ctx    *core.AppContext
+	Config *Config
+	Logger *slog.Logger
+
+	users         *users.UsersModule
+	lampa         *lampa.LampaModule
+	subscriptions *subscriptions.SubscriptionsModule
+}
+
+func (m *AccountModule) Name() string                  { return "account" }
+func (m *AccountModule) Deps() []string                { return []string{"users"} }
+func (m *AccountModule) OptionalDeps() []string        { return []string{"lampa", "subscriptions"} }
+func (m *AccountModule) SetLogger(logger *slog.Logger) { m.Logger = logger }
+func (m *AccountModule) Init(ctx *core.AppContext, logger *slog.Logger, cfg any) error {
+	m.Ctx = ctx
+	m.Config = cfg.(*Config)
+	m.Logger = logger
+	if err := core.Require(ctx, &m.users); err != nil {
+		return err
+	}
+	core.Lookup(ctx, &m.lampa)
+	core.Lookup(ctx, &m.subscriptions)
+
+	m.registerExportEndpoint()
+	m.registerDeleteEndpoint()
+
+	m.Logger.Info("Account module initialized", "Config", m.Config)
+	return nil
+}
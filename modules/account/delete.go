@@ -0,0 +1,127 @@
+package account
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/docker-pet/backend/core"
+	"github.com/docker-pet/backend/models"
+	"github.com/docker-pet/backend/modules/users"
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/pocketbase/pocketbase/apis"
+	pbCore "github.com/pocketbase/pocketbase/core"
+)
+
+const deleteConfirmationPurpose = "account_delete"
+
+func (m *AccountModule) registerDeleteEndpoint() {
+	m.Ctx.App.OnServe().BindFunc(func(se *pbCore.ServeEvent) error {
+		// Two steps: a request without a confirmation returns a short-lived
+		// token, sending it back deletes the account
+		se.Router.POST("/api/me/delete", func(e *pbCore.RequestEvent) error {
+			var body struct {
+				Confirmation string `json:"confirmation"`
+			}
+			if err := e.BindBody(&body); err != nil {
+				return e.BadRequestError(m.Ctx.T(e, "account.body_invalid"), err)
+			}
+
+			user := users.ProxyUser(e.Auth)
+
+			if body.Confirmation == "" {
+				expires := time.Now().Add(m.Config.DeleteConfirmationLifetime)
+				token, err := m.newDeleteConfirmation(user, expires)
+				if err != nil {
+					return e.InternalServerError(m.Ctx.T(e, "account.delete_failed"), err)
+				}
+
+				return e.JSON(http.StatusAccepted, map[string]any{
+					"confirmation": token,
+					"expires":      expires.UTC(),
+				})
+			}
+
+			if err := m.verifyDeleteConfirmation(user, body.Confirmation); err != nil {
+				return e.BadRequestError(m.Ctx.T(e, "account.confirmation_invalid"), err)
+			}
+
+			if err := m.deleteUser(e, user); err != nil {
+				return e.InternalServerError(m.Ctx.T(e, "account.delete_failed"), err)
+			}
+
+			return e.NoContent(http.StatusNoContent)
+		}).Bind(apis.RequireAuth("users"))
+
+		return se.Next()
+	})
+}
+
+// The confirmation is signed with the user's token key, so it is bound to
+// the user and invalidated together with their auth tokens.
+func (m *AccountModule) newDeleteConfirmation(user *models.User, expires time.Time) (string, error) {
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
+		"id":      user.Id,
+		"purpose": deleteConfirmationPurpose,
+		"exp":     expires.Unix(),
+	})
+	return token.SignedString([]byte(user.TokenKey() + deleteConfirmationPurpose))
+}
+
+func (m *AccountModule) verifyDeleteConfirmation(user *models.User, confirmation string) error {
+	token, err := jwt.Parse(confirmation, func(token *jwt.Token) (interface{}, error) {
+		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
+			return nil, errors.New("unexpected signing method")
+		}
+		return []byte(user.TokenKey() + deleteConfirmationPurpose), nil
+	})
+	if err != nil || !token.Valid {
+		return errors.New("invalid or expired confirmation")
+	}
+
+	claims, _ := token.Claims.(jwt.MapClaims)
+	if claims["id"] != user.Id || claims["purpose"] != deleteConfirmationPurpose {
+		return errors.New("confirmation was issued for another action")
+	}
+	return nil
+}
+
+// deleteUser removes the Lampa user and the user in one transaction. The
+// regular hooks run after the commit: lampa rebuilds init.conf without the
+// accsdb entry and outline reconfigures Caddy without the user's key.
+// The audit entry is written in the same transaction. Earlier audit entries
+// and config revisions keep existing, with the user relation cleared.
+func (m *AccountModule) deleteUser(e *pbCore.RequestEvent, user *models.User) error {
+	err := m.Ctx.App.RunInTransaction(func(txApp pbCore.App) error {
+		if m.lampa != nil {
+			if lampaUser, err := m.lampa.GetLampaUserByUserId(user.Id); err == nil {
+				lampaRecord, err := txApp.FindRecordById("lampa_users", lampaUser.Id)
+				if err != nil {
+					return err
+				}
+				if err := txApp.DeleteWithContext(e.Request.Context(), lampaRecord); err != nil {
+					return err
+				}
+			}
+		}
+
+		if err := txApp.DeleteWithContext(e.Request.Context(), user.Record); err != nil {
+			return err
+		}
+
+		m.Ctx.AuditWith(txApp, core.AuditEntry{
+			Module:     m.Name(),
+			Action:     core.AuditAccountDeleted,
+			ActorLabel: core.AuditActorSystem,
+			Before:     map[string]any{"userId": user.Id},
+			IP:         e.RealIP(),
+		})
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	core.Logger(e.Request.Context(), m.Logger).Info("User deleted their account", "UserId", user.Id)
+	return nil
+}
@@ -0,0 +1,180 @@
+package account
+
+import (
+	"archive/zip"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"path"
+	"time"
+
+	"github.com/docker-pet/backend/models"
+	"github.com/docker-pet/backend/modules/users"
+	"github.com/pocketbase/dbx"
+	"github.com/pocketbase/pocketbase/apis"
+	pbCore "github.com/pocketbase/pocketbase/core"
+)
+
+// Auth fields that are credentials rather than data about the user
+var excludedUserFields = []string{"password", "tokenKey"}
+
+type dataExport struct {
+	Exported        time.Time        `json:"exported"`
+	User            map[string]any   `json:"user"`
+	LampaUser       map[string]any   `json:"lampaUser,omitempty"`
+	Subscriptions   []map[string]any `json:"subscriptions,omitempty"`
+	AuditLog        []map[string]any `json:"auditLog"`
+	ConfigRevisions []map[string]any `json:"configRevisions"`
+}
+
+func (m *AccountModule) registerExportEndpoint() {
+	m.Ctx.App.OnServe().BindFunc(func(se *pbCore.ServeEvent) error {
+		// JSON by default, ?format=zip adds the avatar file
+		se.Router.GET("/api/me/export", func(e *pbCore.RequestEvent) error {
+			user := users.ProxyUser(e.Auth)
+			export, err := m.exportUser(user)
+			if err != nil {
+				return e.InternalServerError(m.Ctx.T(e, "account.export_failed"), err)
+			}
+
+			e.Response.Header().Set("Cache-Control", "no-store")
+			filename := fmt.Sprintf("export-%s-%s", user.Id, export.Exported.Format("20060102"))
+
+			switch e.Request.URL.Query().Get("format") {
+			case "", "json":
+				e.Response.Header().Set("Content-Disposition", `attachment; filename="`+filename+`.json"`)
+				return e.JSON(http.StatusOK, export)
+			case "zip":
+				e.Response.Header().Set("Content-Type", "application/zip")
+				e.Response.Header().Set("Content-Disposition", `attachment; filename="`+filename+`.zip"`)
+				e.Response.WriteHeader(http.StatusOK)
+				return m.writeZip(e.Response, user, export)
+			default:
+				return e.BadRequestError(m.Ctx.T(e, "account.export_format_invalid"), nil)
+			}
+		}).Bind(apis.RequireAuth("users"))
+
+		return se.Next()
+	})
+}
+
+func (m *AccountModule) exportUser(user *models.User) (*dataExport, error) {
+	export := &dataExport{
+		Exported:        time.Now().UTC(),
+		User:            user.FieldsData(),
+		AuditLog:        []map[string]any{},
+		ConfigRevisions: []map[string]any{},
+	}
+	for _, field := range excludedUserFields {
+		delete(export.User, field)
+	}
+
+	if m.lampa != nil {
+		if lampaUser, err := m.lampa.GetLampaUserByUserId(user.Id); err == nil {
+			export.LampaUser = lampaUser.FieldsData()
+		}
+	}
+
+	if m.subscriptions != nil {
+		periods, err := m.subscriptions.GetUserPeriods(user.Id)
+		if err != nil {
+			return nil, err
+		}
+		for _, period := range periods {
+			export.Subscriptions = append(export.Subscriptions, period.FieldsData())
+		}
+	}
+
+	auditRecords, err := m.Ctx.App.FindRecordsByFilter(
+		"audit_log",
+		"target = {:user} || actor = {:user}",
+		"-@rowid",
+		0,
+		0,
+		dbx.Params{"user": user.Id},
+	)
+	if err != nil {
+		return nil, err
+	}
+	for _, record := range auditRecords {
+		export.AuditLog = append(export.AuditLog, exportAuditEntry(record, user.Id))
+	}
+
+	// Snapshots hold config secrets, only the revision metadata is exported
+	revisions, err := m.Ctx.App.FindAllRecords("config_revisions", dbx.HashExp{"author": user.Id})
+	if err != nil {
+		return nil, err
+	}
+	for _, record := range revisions {
+		export.ConfigRevisions = append(export.ConfigRevisions, map[string]any{
+			"id":             record.Id,
+			"collectionName": record.GetString("configCollection"),
+			"changedFields":  record.Get("changedFields"),
+			"note":           record.GetString("note"),
+			"created":        record.GetDateTime("created"),
+		})
+	}
+
+	return export, nil
+}
+
+// exportAuditEntry keeps who acted and from where only for the user's own
+// actions, entries written by admins must not reveal them.
+func exportAuditEntry(record *pbCore.Record, userId string) map[string]any {
+	entry := map[string]any{
+		"action":  record.GetString("action"),
+		"module":  record.GetString("module"),
+		"created": record.GetDateTime("created"),
+		"before":  record.Get("before"),
+		"after":   record.Get("after"),
+	}
+	if record.GetString("actor") == userId {
+		entry["actor"] = userId
+		entry["ip"] = record.GetString("ip")
+	}
+	return entry
+}
+
+func (m *AccountModule) writeZip(w io.Writer, user *models.User, export *dataExport) error {
+	archive := zip.NewWriter(w)
+
+	file, err := archive.Create("export.json")
+	if err != nil {
+		return err
+	}
+	encoder := json.NewEncoder(file)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(export); err != nil {
+		return err
+	}
+
+	if avatar := user.GetString("avatar"); avatar != "" {
+		if err := m.copyAvatar(archive, user, avatar); err != nil {
+			m.Logger.Warn("Failed to add avatar to the data export", "UserId", user.Id, "Error", err)
+		}
+	}
+
+	return archive.Close()
+}
+
+func (m *AccountModule) copyAvatar(archive *zip.Writer, user *models.User, avatar string) error {
+	fsys, err := m.Ctx.App.NewFilesystem()
+	if err != nil {
+		return err
+	}
+	defer fsys.Close()
+
+	reader, err := fsys.GetReader(path.Join(user.BaseFilesPath(), avatar))
+	if err != nil {
+		return err
+	}
+	defer reader.Close()
+
+	file, err := archive.Create("avatar" + path.Ext(avatar))
+	if err != nil {
+		return err
+	}
+	_, err = io.Copy(file, reader)
+	return err
+}
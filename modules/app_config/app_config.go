@@ -1,14 +1,26 @@
 package app_config
 
 import (
+	"errors"
 	"log/slog"
+	"net/url"
 
 	"github.com/docker-pet/backend/core"
 	"github.com/docker-pet/backend/models"
 )
 
 type Config struct {
-	Version models.AppVersion
+	Version models.AppVersion `yaml:"-"` // Injected at build time
+
+	TelegramApiUrl string `yaml:"telegramApiUrl"` // Telegram Bot API base URL of the bot and the live validation, e.g. a local bot-api server
+	LiveValidation bool   `yaml:"liveValidation"` // Check a changed bot token and channel ids against the Telegram API before saving
+}
+
+func (c *Config) Validate() error {
+	if u, err := url.Parse(c.TelegramApiUrl); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return errors.New("telegramApiUrl must be an http(s) URL")
+	}
+	return nil
 }
 
 type AppConfigModule struct {
@@ -29,6 +41,7 @@ func (m *AppConfigModule) Init(ctx *core.AppContext, logger *slog.Logger, cfg an
 
 	m.setupVersionSync()
 	m.watchChanges()
+	m.validateChanges()
 
 	m.Logger.Info("App config module initialized", "Config", m.Config)
 	return nil
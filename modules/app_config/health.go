@@ -0,0 +1,14 @@
+package app_config
+
+import (
+	"context"
+	"fmt"
+)
+
+func (m *AppConfigModule) HealthCheck(ctx context.Context) error {
+	if _, err := m.Ctx.App.FindFirstRecordByFilter("app", ""); err != nil {
+		return fmt.Errorf("app config record not found: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,251 @@
+package app_config
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/docker-pet/backend/core"
+	"github.com/docker-pet/backend/i18n"
+	"github.com/docker-pet/backend/models"
+	validation "github.com/go-ozzo/ozzo-validation/v4"
+	pbCore "github.com/pocketbase/pocketbase/core"
+)
+
+const (
+	MinAuthPinLength = 4
+	MaxAuthPinLength = 8
+)
+
+var (
+	botTokenPattern   = regexp.MustCompile(`^\d{5,16}:[A-Za-z0-9_-]{30,64}$`)
+	domainPattern     = regexp.MustCompile(`^([a-z0-9]+(-[a-z0-9]+)*\.)+[a-z]{2,}$`)
+	inviteLinkPattern = regexp.MustCompile(`^https://t\.me/\+[A-Za-z0-9_-]{8,}$`)
+)
+
+// configCheck validates one field. The error is reported only when the
+// field or one of its related fields is changed, so unrelated saves (e.g.
+// the version sync) are not blocked by values set before the checks existed.
+type configCheck struct {
+	field   string
+	related []string
+	check   func(config *models.AppConfig) *checkError
+}
+
+// checkError is a validation error with its i18n key.
+type checkError struct {
+	key  string
+	args i18n.Args
+}
+
+func invalid(key string, args ...i18n.Args) *checkError {
+	err := &checkError{key: "app_config.validation." + key}
+	if len(args) > 0 {
+		err.args = args[0]
+	}
+	return err
+}
+
+var configChecks = []configCheck{
+	{
+		field: "telegramBotToken",
+		check: func(c *models.AppConfig) *checkError {
+			if !botTokenPattern.MatchString(c.TelegramBotToken()) {
+				return invalid("bot_token")
+			}
+			return nil
+		},
+	},
+	{
+		field: "telegramChannelId",
+		check: func(c *models.AppConfig) *checkError {
+			return checkChannelId(c.TelegramChannelId())
+		},
+	},
+	{
+		field:   "telegramPremiumChannelId",
+		related: []string{"telegramChannelId"},
+		check: func(c *models.AppConfig) *checkError {
+			if err := checkChannelId(c.TelegramPremiumChannelId()); err != nil {
+				return err
+			}
+			if c.TelegramPremiumChannelId() == c.TelegramChannelId() {
+				return invalid("channel_id_distinct")
+			}
+			return nil
+		},
+	},
+	{
+		field: "telegramChannelInviteLink",
+		check: func(c *models.AppConfig) *checkError {
+			return checkInviteLink(c.TelegramChannelInviteLink())
+		},
+	},
+	{
+		field: "telegramPremiumChannelInviteLink",
+		check: func(c *models.AppConfig) *checkError {
+			return checkInviteLink(c.TelegramPremiumChannelInviteLink())
+		},
+	},
+	{
+		field: "authPinLength",
+		check: func(c *models.AppConfig) *checkError {
+			if c.AuthPinLength() < MinAuthPinLength || c.AuthPinLength() > MaxAuthPinLength {
+				return invalid("pin_length", i18n.Args{"min": MinAuthPinLength, "max": MaxAuthPinLength})
+			}
+			return nil
+		},
+	},
+	{
+		field: "appDomain",
+		check: func(c *models.AppConfig) *checkError {
+			if !domainPattern.MatchString(c.AppDomain()) {
+				return invalid("domain")
+			}
+			return nil
+		},
+	},
+	{
+		field:   "appDomainReverse",
+		related: []string{"appDomain"},
+		check: func(c *models.AppConfig) *checkError {
+			reverse := c.AppDomainReverse()
+			if reverse == "" {
+				return nil
+			}
+			if !domainPattern.MatchString(reverse) {
+				return invalid("domain")
+			}
+			if reverse == c.AppDomain() {
+				return invalid("domain_reverse_same")
+			}
+			return nil
+		},
+	},
+}
+
+func checkChannelId(id int64) *checkError {
+	if id >= 0 {
+		return invalid("channel_id")
+	}
+	return nil
+}
+
+// checkInviteLink allows an empty link, so admins can clear it.
+func checkInviteLink(link string) *checkError {
+	if link != "" && !inviteLinkPattern.MatchString(link) {
+		return invalid("invite_link")
+	}
+	return nil
+}
+
+// ValidateAppConfig runs the semantic checks of the given fields, or of all
+// fields when none are given. Messages are translated into the locale.
+func ValidateAppConfig(catalog *i18n.Catalog, locale string, config *models.AppConfig, changedFields ...string) validation.Errors {
+	errs := validation.Errors{}
+	for _, check := range configChecks {
+		if len(changedFields) > 0 &&
+			!slices.Contains(changedFields, check.field) &&
+			!slices.ContainsFunc(check.related, func(field string) bool { return slices.Contains(changedFields, field) }) {
+			continue
+		}
+
+		if err := check.check(config); err != nil {
+			errs[check.field] = validation.NewError("validation_invalid_app_config", catalog.T(locale, err.key, err.args))
+		}
+	}
+	return errs
+}
+
+// validateChanges rejects app config updates with invalid values. The
+// errors are returned per field, so the admin UI shows them next to inputs.
+func (m *AppConfigModule) validateChanges() {
+	m.Ctx.App.OnRecordUpdate("app").BindFunc(func(e *pbCore.RecordEvent) error {
+		original := e.Record.Original()
+		var changed []string
+		for _, field := range e.Record.Collection().Fields.FieldNames() {
+			if fmt.Sprint(original.Get(field)) != fmt.Sprint(e.Record.Get(field)) {
+				changed = append(changed, field)
+			}
+		}
+		if len(changed) == 0 {
+			return e.Next()
+		}
+
+		// The update is not bound to a request here, so messages use the
+		// fallback locale
+		locale := m.Ctx.I18n.FallbackLocale()
+		config := ProxyAppConfig(e.Record)
+		errs := ValidateAppConfig(m.Ctx.I18n, locale, config, changed...)
+
+		if len(errs) == 0 && m.Config.LiveValidation {
+			for field, err := range m.checkTelegram(e.Context, locale, config, changed) {
+				errs[field] = err
+			}
+		}
+
+		if len(errs) > 0 {
+			core.Logger(e.Context, m.Logger).Info("Rejected invalid app config update", "Error", errs)
+			return errs
+		}
+		return e.Next()
+	})
+}
+
+// checkTelegram verifies the token and the channels against the Telegram
+// Bot API when they are changed.
+func (m *AppConfigModule) checkTelegram(ctx context.Context, locale string, config *models.AppConfig, changed []string) validation.Errors {
+	errs := validation.Errors{}
+	tokenChanged := slices.Contains(changed, "telegramBotToken")
+
+	if tokenChanged {
+		if err := m.telegramRequest(ctx, config.TelegramBotToken(), "getMe", nil); err != nil {
+			errs["telegramBotToken"] = validation.NewError("validation_telegram_rejected", m.Ctx.I18n.T(locale, err.key, err.args))
+			return errs
+		}
+	}
+
+	channels := map[string]int64{
+		"telegramChannelId":        config.TelegramChannelId(),
+		"telegramPremiumChannelId": config.TelegramPremiumChannelId(),
+	}
+	for field, chatId := range channels {
+		if !tokenChanged && !slices.Contains(changed, field) {
+			continue
+		}
+		params := map[string]string{"chat_id": fmt.Sprint(chatId)}
+		if err := m.telegramRequest(ctx, config.TelegramBotToken(), "getChat", params); err != nil {
+			errs[field] = validation.NewError("validation_telegram_rejected", m.Ctx.I18n.T(locale, err.key, err.args))
+		}
+	}
+
+	return errs
+}
+
+func (m *AppConfigModule) telegramRequest(ctx context.Context, token string, method string, params map[string]string) *checkError {
+	var result struct {
+		Ok          bool   `json:"ok"`
+		Description string `json:"description"`
+	}
+
+	url := fmt.Sprintf("%s/bot%s/%s", strings.TrimRight(m.Config.TelegramApiUrl, "/"), token, method)
+	response, err := m.Ctx.HttpClients.Client(core.HttpProfileDefault).R().
+		SetContext(ctx).
+		SetQueryParams(params).
+		SetResult(&result).
+		SetError(&result).
+		Get(url)
+	if err != nil {
+		return invalid("telegram_unreachable")
+	}
+
+	if !result.Ok {
+		if result.Description == "" {
+			result.Description = response.Status()
+		}
+		return invalid("telegram_rejected", i18n.Args{"reason": result.Description})
+	}
+	return nil
+}
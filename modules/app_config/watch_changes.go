@@ -2,15 +2,14 @@ package app_config
 
 import (
 	"fmt"
-	"time"
 
-	"github.com/docker-pet/backend/models"
-	"github.com/pocketbase/pocketbase/core"
+	"github.com/docker-pet/backend/core"
+	pbCore "github.com/pocketbase/pocketbase/core"
 )
 
 func (m *AppConfigModule) watchChanges() {
 	// Delete
-	m.Ctx.App.OnRecordDelete("app").BindFunc(func(e *core.RecordEvent) error {
+	m.Ctx.App.OnRecordDelete("app").BindFunc(func(e *pbCore.RecordEvent) error {
 		if e.Record.Id != m.AppConfig().Id {
 			return e.Next()
 		}
@@ -20,7 +19,7 @@ func (m *AppConfigModule) watchChanges() {
 	})
 
 	// Create
-	m.Ctx.App.OnRecordCreate("app").BindFunc(func(e *core.RecordEvent) error {
+	m.Ctx.App.OnRecordCreate("app").BindFunc(func(e *pbCore.RecordEvent) error {
 		if m.currentConfig == nil {
 			return e.Next()
 		}
@@ -29,32 +28,18 @@ func (m *AppConfigModule) watchChanges() {
 		return fmt.Errorf("cannot create a new app config record while one already exists: %s", m.AppConfig().Id)
 	})
 
-	// Update
-	m.Ctx.App.OnRecordAfterUpdateSuccess("app").BindFunc(func(e *core.RecordEvent) error {
+	// Update, modules react to AppConfigChanged in place
+	m.Ctx.App.OnRecordAfterUpdateSuccess("app").BindFunc(func(e *pbCore.RecordEvent) error {
 		if e.Record.Id != m.AppConfig().Id {
 			return e.Next()
 		}
 
+		oldConfig := ProxyAppConfig(e.Record.Original())
 		newConfig := ProxyAppConfig(e.Record)
-		m.RestartAppIfNeeded(newConfig)
 		m.currentConfig = newConfig
 
 		m.Logger.Info("App config updated successfully")
-		return nil
+		core.Publish(m.Ctx, core.AppConfigChanged{Old: oldConfig, New: newConfig})
+		return e.Next()
 	})
 }
-
-func (m *AppConfigModule) RestartAppIfNeeded(config *models.AppConfig) {
-	if m.AppConfig().TelegramBotToken() != config.TelegramBotToken() ||
-		m.AppConfig().AuthPinLength() != config.AuthPinLength() ||
-		m.AppConfig().AppDomain() != config.AppDomain() {
-		m.Logger.Info("Restarting app due to config changes")
-		go func() {
-			time.Sleep(3 * time.Second)
-			err := m.Ctx.App.Restart()
-			if err != nil {
-				panic(fmt.Errorf("failed to restart app: %w", err))
-			}
-		}()
-	}
-}
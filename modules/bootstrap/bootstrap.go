@@ -0,0 +1,47 @@
+package bootstrap
+
+import (
+	"errors"
+	"log/slog"
+
+	"github.com/docker-pet/backend/core"
+	"github.com/docker-pet/backend/modules/app_config"
+	"github.com/docker-pet/backend/modules/outline"
+	"github.com/docker-pet/backend/modules/users"
+)
+
+type Config struct{}
+
+// BootstrapModule serves the payload the frontends need on start in a
+// single request.
+type BootstrapModule struct {
+	Ctx    *core.AppContext
+	Config *Config
+	Logger *slog.Logger
+
+	appConfig *app_config.AppConfigModule
+	users     *users.UsersModule
+	outline   *outline.OutlineModule
+}
+
+func (m *BootstrapModule) Name() string                  { return "bootstrap" }
+func (m *BootstrapModule) Deps() []string                { return []string{"app_config", "users"} }
+func (m *BootstrapModule) OptionalDeps() []string        { return []string{"outline"} }
+func (m *BootstrapModule) SetLogger(logger *slog.Logger) { m.Logger = logger }
+func (m *BootstrapModule) Init(ctx *core.AppContext, logger *slog.Logger, cfg any) error {
+	m.Ctx = ctx
+	m.Config = cfg.(*Config)
+	m.Logger = logger
+	if err := errors.Join(
+		core.Require(ctx, &m.appConfig),
+		core.Require(ctx, &m.users),
+	); err != nil {
+		return err
+	}
+	core.Lookup(ctx, &m.outline)
+
+	m.registerBootstrapEndpoint()
+
+	m.Logger.Info("Bootstrap module initialized")
+	return nil
+}
@@ -0,0 +1,187 @@
+package bootstrap
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/docker-pet/backend/models"
+	"github.com/docker-pet/backend/modules/users"
+	pbCore "github.com/pocketbase/pocketbase/core"
+)
+
+// Modules reported as feature flags, keyed by the payload name
+var featureModules = map[string]string{
+	"outline":         "outline",
+	"lampa":           "lampa",
+	"otpAuth":         "otp_auth",
+	"telegramBot":     "telegram_bot",
+	"telegramMiniapp": "telegram_miniapp",
+}
+
+type bootstrapPayload struct {
+	App            appInfo            `json:"app"`
+	Version        *models.AppVersion `json:"version"`
+	Features       map[string]bool    `json:"features"`
+	Locale         string             `json:"locale"`
+	User           *userInfo          `json:"user"`
+	OutlineServers []outlineServer    `json:"outlineServers"`
+}
+
+type appInfo struct {
+	Title                    string `json:"title"`
+	Domain                   string `json:"domain"`
+	BotUsername              string `json:"botUsername"`
+	SupportLink              string `json:"supportLink"`
+	ChannelInviteLink        string `json:"channelInviteLink"`
+	PremiumChannelInviteLink string `json:"premiumChannelInviteLink,omitempty"` // Premium users and admins only
+}
+
+type userInfo struct {
+	Id               string          `json:"id"`
+	Name             string          `json:"name"`
+	TelegramUsername string          `json:"telegramUsername"`
+	Language         string          `json:"language"`
+	Role             models.UserRole `json:"role"`
+	Premium          bool            `json:"premium"`
+	JoinPending      bool            `json:"joinPending"`
+	Avatar           string          `json:"avatar"`
+	Outline          *outlineInfo    `json:"outline,omitempty"`
+}
+
+type outlineInfo struct {
+	PrefixEnabled        bool   `json:"prefixEnabled"`
+	ReverseServerEnabled bool   `json:"reverseServerEnabled"`
+	Server               string `json:"server"`
+}
+
+type outlineServer struct {
+	Id       string `json:"id"`
+	Slug     string `json:"slug"`
+	Country  string `json:"country"`
+	Premium  bool   `json:"premium"`
+	Autopick bool   `json:"autopick"`
+}
+
+func (m *BootstrapModule) registerBootstrapEndpoint() {
+	m.Ctx.App.OnServe().BindFunc(func(se *pbCore.ServeEvent) error {
+		se.Router.GET("/api/bootstrap", func(e *pbCore.RequestEvent) error {
+			payload, err := m.buildPayload(e)
+			if err != nil {
+				return e.InternalServerError(m.Ctx.T(e, "bootstrap.build_failed"), err)
+			}
+
+			body, err := json.Marshal(payload)
+			if err != nil {
+				return e.InternalServerError(m.Ctx.T(e, "bootstrap.build_failed"), err)
+			}
+
+			// Clients revalidate on every load and get 304 while nothing
+			// has changed
+			sum := sha256.Sum256(body)
+			etag := `"` + hex.EncodeToString(sum[:16]) + `"`
+
+			header := e.Response.Header()
+			header.Set("ETag", etag)
+			header.Set("Vary", "Authorization, Accept-Language")
+			if payload.User == nil {
+				header.Set("Cache-Control", "public, no-cache")
+			} else {
+				header.Set("Cache-Control", "private, no-cache")
+			}
+
+			if etagMatches(e.Request.Header.Get("If-None-Match"), etag) {
+				return e.NoContent(http.StatusNotModified)
+			}
+
+			return e.Blob(http.StatusOK, "application/json", body)
+		})
+
+		return se.Next()
+	})
+}
+
+func (m *BootstrapModule) buildPayload(e *pbCore.RequestEvent) (*bootstrapPayload, error) {
+	appConfig := m.appConfig.AppConfig()
+
+	payload := &bootstrapPayload{
+		App: appInfo{
+			Title:             appConfig.AppTitle(),
+			Domain:            appConfig.AppDomain(),
+			BotUsername:       appConfig.BotUsername(),
+			SupportLink:       appConfig.SupportLink(),
+			ChannelInviteLink: appConfig.TelegramChannelInviteLink(),
+		},
+		Version:        appConfig.Version(),
+		Features:       map[string]bool{},
+		Locale:         m.Ctx.RequestLocale(e),
+		OutlineServers: []outlineServer{},
+	}
+
+	for feature, module := range featureModules {
+		payload.Features[feature] = m.Ctx.ModuleEnabled(module)
+	}
+
+	if e.Auth == nil || e.Auth.Collection().Name != "users" {
+		return payload, nil
+	}
+
+	user := users.ProxyUser(e.Auth)
+	premium := user.EffectivePremium(time.Now())
+	payload.User = &userInfo{
+		Id:               user.Id,
+		Name:             user.Name(),
+		TelegramUsername: user.TelegramUsername(),
+		Language:         user.Language(),
+		Role:             user.Role(),
+		Premium:          premium,
+		JoinPending:      user.JoinPending(),
+		Avatar:           user.GetString("avatar"),
+	}
+
+	if premium || user.Role() == models.RoleAdmin {
+		payload.App.PremiumChannelInviteLink = appConfig.TelegramPremiumChannelInviteLink()
+	}
+
+	if m.outline != nil && user.IsActive() {
+		payload.User.Outline = &outlineInfo{
+			PrefixEnabled:        user.OutlinePrefixEnabled(),
+			ReverseServerEnabled: user.OutlineReverseServerEnabled(),
+			Server:               user.OutlineServer(),
+		}
+
+		servers, err := m.outline.GetAllActiveServers()
+		if err != nil {
+			return nil, err
+		}
+		for _, server := range servers {
+			if server.Premium() && !premium {
+				continue
+			}
+			payload.OutlineServers = append(payload.OutlineServers, outlineServer{
+				Id:       server.Id,
+				Slug:     server.Slug(),
+				Country:  server.Country().Alpha2(),
+				Premium:  server.Premium(),
+				Autopick: server.Autopick(),
+			})
+		}
+	}
+
+	return payload, nil
+}
+
+// etagMatches checks an If-None-Match header, which may list several
+// (possibly weak) tags or "*".
+func etagMatches(header string, etag string) bool {
+	for _, candidate := range strings.Split(header, ",") {
+		candidate = strings.TrimPrefix(strings.TrimSpace(candidate), "W/")
+		if candidate == etag || candidate == "*" {
+			return true
+		}
+	}
+	return false
+}
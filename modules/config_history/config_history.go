@@ -0,0 +1,45 @@
+package config_history
+
+import (
+	"errors"
+	"log/slog"
+	"sync"
+
+	"github.com/docker-pet/backend/core"
+)
+
+type Config struct {
+	ListLimit int `yaml:"listLimit"` // Maximum revisions returned by the list endpoint
+}
+
+func (c *Config) Validate() error {
+	if c.ListLimit < 1 {
+		return errors.New("listLimit must be at least 1")
+	}
+	return nil
+}
+
+type ConfigHistoryModule struct {
+	Ctx    *core.AppContext
+	Config *Config
+	Logger *slog.Logger
+
+	authorsMu sync.Mutex
+	authors   map[string]revisionAuthor // Pending authors keyed by collection/record id
+}
+
+func (m *ConfigHistoryModule) Name() string                  { return "config_history" }
+func (m *ConfigHistoryModule) Deps() []string                { return nil }
+func (m *ConfigHistoryModule) SetLogger(logger *slog.Logger) { m.Logger = logger }
+func (m *ConfigHistoryModule) Init(ctx *core.AppContext, logger *slog.Logger, cfg any) error {
+	m.Ctx = ctx
+	m.Config = cfg.(*Config)
+	m.Logger = logger
+	m.authors = map[string]revisionAuthor{}
+
+	m.trackChanges()
+	m.registerRevisionsEndpoints()
+
+	m.Logger.Info("Config history module initialized", "Config", m.Config)
+	return nil
+}
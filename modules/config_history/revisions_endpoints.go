@@ -0,0 +1,199 @@
+package config_history
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/docker-pet/backend/core"
+	"github.com/docker-pet/backend/models"
+	"github.com/pocketbase/dbx"
+	pbCore "github.com/pocketbase/pocketbase/core"
+	"github.com/pocketbase/pocketbase/tools/types"
+)
+
+const maskedValue = "********"
+
+type revisionResponse struct {
+	Id             string         `json:"id"`
+	CollectionName string         `json:"collectionName"`
+	RecordId       string         `json:"recordId"`
+	ChangedFields  []string       `json:"changedFields"`
+	AuthorId       string         `json:"author"`
+	AuthorLabel    string         `json:"authorLabel"`
+	Note           string         `json:"note"`
+	Created        types.DateTime `json:"created"`
+}
+
+type fieldChange struct {
+	Field  string `json:"field"`
+	Before any    `json:"before"`
+	After  any    `json:"after"`
+	Secret bool   `json:"secret"`
+}
+
+func (m *ConfigHistoryModule) registerRevisionsEndpoints() {
+	m.Ctx.App.OnServe().BindFunc(func(se *pbCore.ServeEvent) error {
+		group := se.Router.Group("/api/config_history")
+		group.Bind(core.RequireAdmin())
+
+		// List revisions, newest first
+		group.GET("/{collection}/revisions", func(e *pbCore.RequestEvent) error {
+			collection := e.Request.PathValue("collection")
+			if _, ok := trackedCollections[collection]; !ok {
+				return e.NotFoundError(m.Ctx.T(e, "config_history.collection_not_tracked"), nil)
+			}
+
+			records, err := m.Ctx.App.FindRecordsByFilter(
+				"config_revisions",
+				"configCollection = {:collection}",
+				"-@rowid",
+				m.Config.ListLimit,
+				0,
+				dbx.Params{"collection": collection},
+			)
+			if err != nil {
+				return e.InternalServerError(m.Ctx.T(e, "config_history.list_failed"), err)
+			}
+
+			revisions := make([]revisionResponse, len(records))
+			for i, record := range records {
+				revisions[i] = toRevisionResponse(proxyRevision(record))
+			}
+
+			return e.JSON(http.StatusOK, revisions)
+		})
+
+		// Diff against the previous revision or, with ?against=current, the
+		// live record
+		group.GET("/revisions/{id}/diff", func(e *pbCore.RequestEvent) error {
+			revision, err := m.findRevision(e.Request.PathValue("id"))
+			if err != nil {
+				return e.NotFoundError(m.Ctx.T(e, "config_history.revision_not_found"), err)
+			}
+			tracked := trackedCollections[revision.CollectionName()]
+
+			var before, after map[string]any
+			against := e.Request.URL.Query().Get("against")
+			switch against {
+			case "current":
+				record, err := m.Ctx.App.FindRecordById(revision.CollectionName(), revision.RecordId())
+				if err != nil {
+					return e.NotFoundError(m.Ctx.T(e, "config_history.record_not_found"), err)
+				}
+				before, after = revision.Snapshot(), snapshot(tracked, record)
+			case "", "previous":
+				against = "previous"
+				before, after = revision.PreviousSnapshot(), revision.Snapshot()
+			default:
+				return e.BadRequestError(m.Ctx.T(e, "config_history.against_invalid"), nil)
+			}
+
+			return e.JSON(http.StatusOK, map[string]any{
+				"revision": toRevisionResponse(revision),
+				"against":  against,
+				"changes":  diff(tracked, before, after),
+			})
+		})
+
+		// Roll back the record to the snapshot of the revision. The update
+		// goes through the regular hooks, so modules rebuild their artifacts
+		// and a new revision is recorded.
+		group.POST("/revisions/{id}/rollback", func(e *pbCore.RequestEvent) error {
+			revision, err := m.findRevision(e.Request.PathValue("id"))
+			if err != nil {
+				return e.NotFoundError(m.Ctx.T(e, "config_history.revision_not_found"), err)
+			}
+
+			record, err := m.Ctx.App.FindRecordById(revision.CollectionName(), revision.RecordId())
+			if err != nil {
+				return e.NotFoundError(m.Ctx.T(e, "config_history.record_not_found"), err)
+			}
+
+			tracked := trackedCollections[revision.CollectionName()]
+			for field, value := range revision.Snapshot() {
+				if tracked.isVersioned(field) && record.Collection().Fields.GetByName(field) != nil {
+					record.Set(field, value)
+				}
+			}
+
+			actor := core.AuditEntry{}.WithRequest(e)
+			m.setPendingAuthor(record, revisionAuthor{
+				Id:    actor.ActorId,
+				Label: actor.ActorLabel,
+				Note:  "rollback to " + revision.Id,
+			})
+			defer m.takePendingAuthor(record)
+
+			if err := m.Ctx.App.SaveWithContext(e.Request.Context(), record); err != nil {
+				return e.BadRequestError(m.Ctx.T(e, "config_history.rollback_failed"), err)
+			}
+
+			m.Ctx.Audit(core.AuditEntry{
+				Module: m.Name(),
+				Action: core.AuditConfigRolledBack,
+				After: map[string]any{
+					"collection": revision.CollectionName(),
+					"revision":   revision.Id,
+				},
+			}.WithRequest(e))
+
+			return e.JSON(http.StatusOK, map[string]any{"ok": true})
+		})
+
+		return se.Next()
+	})
+}
+
+func (m *ConfigHistoryModule) findRevision(id string) (*models.ConfigRevision, error) {
+	record, err := m.Ctx.App.FindRecordById("config_revisions", id)
+	if err != nil {
+		return nil, err
+	}
+
+	revision := proxyRevision(record)
+	if _, ok := trackedCollections[revision.CollectionName()]; !ok {
+		return nil, fmt.Errorf("collection %s has no config history", revision.CollectionName())
+	}
+	return revision, nil
+}
+
+// diff lists the changed fields with secret values masked.
+func diff(tracked trackedCollection, before map[string]any, after map[string]any) []fieldChange {
+	changes := []fieldChange{}
+	for _, field := range changedFields(tracked, before, after) {
+		change := fieldChange{Field: field, Before: before[field], After: after[field]}
+		if tracked.isSecret(field) {
+			change.Secret = true
+			change.Before = mask(change.Before)
+			change.After = mask(change.After)
+		}
+		changes = append(changes, change)
+	}
+	return changes
+}
+
+func mask(value any) any {
+	if value == nil || canonicalJSON(value) == `""` {
+		return ""
+	}
+	return maskedValue
+}
+
+func proxyRevision(record *pbCore.Record) *models.ConfigRevision {
+	revision := &models.ConfigRevision{}
+	revision.SetProxyRecord(record)
+	return revision
+}
+
+func toRevisionResponse(revision *models.ConfigRevision) revisionResponse {
+	return revisionResponse{
+		Id:             revision.Id,
+		CollectionName: revision.CollectionName(),
+		RecordId:       revision.RecordId(),
+		ChangedFields:  revision.ChangedFields(),
+		AuthorId:       revision.AuthorId(),
+		AuthorLabel:    revision.AuthorLabel(),
+		Note:           revision.Note(),
+		Created:        revision.Created(),
+	}
+}
@@ -0,0 +1,157 @@
+package config_history
+
+import (
+	"encoding/json"
+	"slices"
+
+	"github.com/docker-pet/backend/core"
+	"github.com/docker-pet/backend/models"
+	"github.com/pocketbase/dbx"
+	pbCore "github.com/pocketbase/pocketbase/core"
+)
+
+type revisionAuthor struct {
+	Id    string // users record id
+	Label string // e.g. "superuser:admin@example.com", "system"
+	Note  string
+}
+
+func (m *ConfigHistoryModule) trackChanges() {
+	for name := range trackedCollections {
+		// Remember who is updating the record through the API
+		m.Ctx.App.OnRecordUpdateRequest(name).BindFunc(func(e *pbCore.RecordRequestEvent) error {
+			actor := core.AuditEntry{}.WithRequest(e.RequestEvent)
+			m.setPendingAuthor(e.Record, revisionAuthor{Id: actor.ActorId, Label: actor.ActorLabel})
+			defer m.takePendingAuthor(e.Record)
+
+			return e.Next()
+		})
+
+		m.Ctx.App.OnRecordAfterUpdateSuccess(name).BindFunc(func(e *pbCore.RecordEvent) error {
+			m.recordRevision(e.Record.Original(), e.Record, m.takePendingAuthor(e.Record))
+			return e.Next()
+		})
+	}
+}
+
+func pendingAuthorKey(record *pbCore.Record) string {
+	return record.Collection().Name + "/" + record.Id
+}
+
+func (m *ConfigHistoryModule) setPendingAuthor(record *pbCore.Record, author revisionAuthor) {
+	m.authorsMu.Lock()
+	defer m.authorsMu.Unlock()
+	m.authors[pendingAuthorKey(record)] = author
+}
+
+func (m *ConfigHistoryModule) takePendingAuthor(record *pbCore.Record) revisionAuthor {
+	m.authorsMu.Lock()
+	defer m.authorsMu.Unlock()
+
+	key := pendingAuthorKey(record)
+	author, ok := m.authors[key]
+	delete(m.authors, key)
+	if !ok || (author.Id == "" && author.Label == "") {
+		author.Label = core.AuditActorSystem
+	}
+	return author
+}
+
+// recordRevision stores a snapshot of the updated record. The first change
+// of a record also stores its previous state as the baseline revision.
+func (m *ConfigHistoryModule) recordRevision(before *pbCore.Record, after *pbCore.Record, author revisionAuthor) {
+	tracked := trackedCollections[after.Collection().Name]
+	previous, current := snapshot(tracked, before), snapshot(tracked, after)
+	changed := changedFields(tracked, previous, current)
+	if len(changed) == 0 {
+		return
+	}
+
+	count, err := m.Ctx.App.CountRecords("config_revisions", dbx.HashExp{
+		"configCollection": after.Collection().Name,
+		"recordId":         after.Id,
+	})
+	if err == nil && count == 0 {
+		err = m.saveRevision(before, previous, nil, nil, revisionAuthor{Label: core.AuditActorSystem, Note: "baseline"})
+	}
+	if err == nil {
+		err = m.saveRevision(after, current, previous, changed, author)
+	}
+
+	if err != nil {
+		m.Logger.Error(
+			"Failed to save config revision",
+			"Error", err,
+			"Collection", after.Collection().Name,
+			"RecordId", after.Id,
+		)
+	}
+}
+
+func (m *ConfigHistoryModule) saveRevision(record *pbCore.Record, current map[string]any, previous map[string]any, changed []string, author revisionAuthor) error {
+	collection, err := m.Ctx.App.FindCollectionByNameOrId("config_revisions")
+	if err != nil {
+		return err
+	}
+
+	revision := &models.ConfigRevision{}
+	revision.SetProxyRecord(pbCore.NewRecord(collection))
+	revision.SetCollectionName(record.Collection().Name)
+	revision.SetRecordId(record.Id)
+	revision.SetSnapshot(current)
+	revision.SetPreviousSnapshot(previous)
+	revision.SetChangedFields(changed)
+	revision.SetAuthorId(author.Id)
+	revision.SetAuthorLabel(author.Label)
+	revision.SetNote(author.Note)
+
+	return m.Ctx.App.Save(revision)
+}
+
+// snapshot returns the versioned field values, secrets included.
+func snapshot(tracked trackedCollection, record *pbCore.Record) map[string]any {
+	data := map[string]any{}
+	for field, value := range record.FieldsData() {
+		if tracked.isVersioned(field) {
+			data[field] = value
+		}
+	}
+	return data
+}
+
+func changedFields(tracked trackedCollection, before map[string]any, after map[string]any) []string {
+	var changed []string
+	for field := range after {
+		if !equalValues(before[field], after[field]) {
+			changed = append(changed, field)
+		}
+	}
+	for field := range before {
+		if _, ok := after[field]; !ok {
+			changed = append(changed, field)
+		}
+	}
+	slices.Sort(changed)
+	return changed
+}
+
+// equalValues compares values by their canonical JSON form, so a snapshot
+// read back from the database equals the live record values.
+func equalValues(a any, b any) bool {
+	return canonicalJSON(a) == canonicalJSON(b)
+}
+
+func canonicalJSON(value any) string {
+	raw, err := json.Marshal(value)
+	if err != nil {
+		return ""
+	}
+
+	var decoded any
+	if err := json.Unmarshal(raw, &decoded); err != nil {
+		return string(raw)
+	}
+
+	raw, _ = json.Marshal(decoded)
+	return string(raw)
+}
@@ -0,0 +1,29 @@
+package config_history
+
+import "slices"
+
+type trackedCollection struct {
+	secretFields  []string // Masked in diffs
+	ignoredFields []string // Managed by the backend, neither versioned nor restored
+}
+
+// trackedCollections are the singleton config collections with history.
+var trackedCollections = map[string]trackedCollection{
+	"app": {
+		secretFields:  []string{"telegramBotToken", "authSecret"},
+		ignoredFields: []string{"version", "botUsername"},
+	},
+	"lampa": {
+		secretFields: []string{"adminPassword", "configInit"},
+	},
+}
+
+var systemFields = []string{"id", "created", "updated"}
+
+func (c trackedCollection) isVersioned(field string) bool {
+	return !slices.Contains(systemFields, field) && !slices.Contains(c.ignoredFields, field)
+}
+
+func (c trackedCollection) isSecret(field string) bool {
+	return slices.Contains(c.secretFields, field)
+}
@@ -2,11 +2,16 @@ package lampa
 
 import (
 	"path/filepath"
+	"time"
 
 	"github.com/Jeffail/gabs/v2"
 	"github.com/docker-pet/backend/helpers"
+	"github.com/docker-pet/backend/models"
 )
 
+// accsdbNoExpiry is the expiry of accounts without an access period.
+const accsdbNoExpiry = "2040-01-01T00:00:00"
+
 func (m *LampaModule) BuildInitConfig() {
 	container := gabs.New()
 
@@ -71,13 +76,31 @@ func (m *LampaModule) BuildInitConfig() {
 		panic(err)
 	}
 
+	// Users with an access period expire with it
+	owners := map[string]*models.User{}
+	if allUsers, err := m.users.GetAllUsers(); err == nil {
+		for _, user := range allUsers {
+			owners[user.Id] = user
+		}
+	} else {
+		m.Logger.Warn("Failed to get users for accsdb expiry", "Err", err)
+	}
+
+	now := time.Now()
 	container.SetP(true, "accsdb.enable")
 	container.ArrayP("accsdb.users")
 	for _, user := range users {
+		expires := accsdbNoExpiry
+		if owner := owners[user.UserId()]; owner != nil {
+			if ends := owner.AccessExpires(now); !ends.IsZero() {
+				expires = ends.Time().UTC().Format("2006-01-02T15:04:05")
+			}
+		}
+
 		userObj := gabs.New()
 		userObj.SetP(user.AuthKey(), "id")
 		userObj.SetP(user.Disabled(), "ban")
-		userObj.SetP("2040-01-01T00:00:00", "expires")
+		userObj.SetP(expires, "expires")
 		container.ArrayAppendP(userObj, "accsdb.users")
 	}
 
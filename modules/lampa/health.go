@@ -0,0 +1,16 @@
+package lampa
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/docker-pet/backend/helpers"
+)
+
+func (m *LampaModule) HealthCheck(ctx context.Context) error {
+	if _, err := m.Ctx.App.FindFirstRecordByFilter("lampa", ""); err != nil {
+		return fmt.Errorf("lampa config record not found: %w", err)
+	}
+
+	return helpers.CheckWritable(m.Config.StoragePath)
+}
@@ -1,16 +1,34 @@
 package lampa
 
 import (
+	"errors"
+	"fmt"
 	"log/slog"
+	"net/url"
 
 	"github.com/docker-pet/backend/core"
 	"github.com/docker-pet/backend/models"
 	"github.com/docker-pet/backend/modules/app_config"
 	"github.com/docker-pet/backend/modules/users"
+	"github.com/zmwangx/debounce"
 )
 
 type Config struct {
-	StoragePath string
+	StoragePath string `yaml:"storagePath"`
+	PublicUrl   string `yaml:"publicUrl"` // URL users open Lampa at, https://lampa.<appDomain> if not set
+}
+
+func (c *Config) Validate() error {
+	var errs []error
+	if c.StoragePath == "" {
+		errs = append(errs, errors.New("storagePath is required"))
+	}
+	if c.PublicUrl != "" {
+		if u, err := url.Parse(c.PublicUrl); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			errs = append(errs, errors.New("publicUrl must be an http(s) URL"))
+		}
+	}
+	return errors.Join(errs...)
 }
 
 type LampaModule struct {
@@ -21,6 +39,8 @@ type LampaModule struct {
 	users              *users.UsersModule
 	appConfig          *app_config.AppConfigModule
 	currentLampaConfig *models.LampaConfig
+
+	buildInitConfigControl debounce.Control
 }
 
 func (m *LampaModule) Name() string                  { return "lampa" }
@@ -30,13 +50,24 @@ func (m *LampaModule) Init(ctx *core.AppContext, logger *slog.Logger, cfg any) e
 	m.Ctx = ctx
 	m.Config = cfg.(*Config)
 	m.Logger = logger
-	m.users = m.Ctx.Modules["users"].(*users.UsersModule)
-	m.appConfig = m.Ctx.Modules["app_config"].(*app_config.AppConfigModule)
+	if err := errors.Join(
+		core.Require(ctx, &m.users),
+		core.Require(ctx, &m.appConfig),
+	); err != nil {
+		return err
+	}
 
 	m.watchConfigChanges()
 	m.watchUsersChanges()
-	go m.afterInit()
 
 	m.Logger.Info("Lampa module initialized", "Config", m.Config)
 	return nil
 }
+
+// PublicUrl is the URL users open Lampa at.
+func (m *LampaModule) PublicUrl() string {
+	if m.Config.PublicUrl != "" {
+		return m.Config.PublicUrl
+	}
+	return fmt.Sprintf("https://lampa.%s", m.appConfig.AppConfig().AppDomain())
+}
@@ -0,0 +1,37 @@
+package lampa
+
+import (
+	"context"
+	"path/filepath"
+
+	"github.com/docker-pet/backend/helpers"
+)
+
+func (m *LampaModule) Start(ctx context.Context) error {
+	cleanPath := filepath.Clean(m.Config.StoragePath)
+	err := helpers.EnsureDir(cleanPath)
+
+	if err != nil {
+		m.Logger.Error(
+			"Failed to ensure lampa configs directory",
+			"Error", err,
+			"Path", cleanPath,
+		)
+		return err
+	}
+
+	m.BuildManifest()
+	m.BuildPassword()
+	m.BuildInitConfig()
+
+	return nil
+}
+
+func (m *LampaModule) Stop(ctx context.Context) error {
+	// Write pending init.conf changes before shutting down
+	if m.buildInitConfigControl.Pending != nil && m.buildInitConfigControl.Pending() {
+		return helpers.WaitContext(ctx, m.buildInitConfigControl.Flush)
+	}
+
+	return nil
+}
@@ -4,20 +4,22 @@ import (
 	"fmt"
 	"time"
 
-	"github.com/pocketbase/pocketbase/core"
+	"github.com/docker-pet/backend/core"
+	pbCore "github.com/pocketbase/pocketbase/core"
 	"github.com/zmwangx/debounce"
 )
 
 func (m *LampaModule) watchConfigChanges() {
-	buildInitConfigDebounced, _ := debounce.Debounce(
+	buildInitConfigDebounced, buildInitConfigControl := debounce.Debounce(
 		func() { m.BuildInitConfig() },
 		10*time.Second,
 		debounce.WithLeading(true),
 		debounce.WithTrailing(true),
 	)
+	m.buildInitConfigControl = buildInitConfigControl
 
 	// Delete
-	m.Ctx.App.OnRecordDelete("lampa").BindFunc(func(e *core.RecordEvent) error {
+	m.Ctx.App.OnRecordDelete("lampa").BindFunc(func(e *pbCore.RecordEvent) error {
 		if e.Record.Id != m.LampaConfig().Id {
 			return e.Next()
 		}
@@ -27,7 +29,7 @@ func (m *LampaModule) watchConfigChanges() {
 	})
 
 	// Create
-	m.Ctx.App.OnRecordCreate("lampa").BindFunc(func(e *core.RecordEvent) error {
+	m.Ctx.App.OnRecordCreate("lampa").BindFunc(func(e *pbCore.RecordEvent) error {
 		if m.currentLampaConfig == nil {
 			return e.Next()
 		}
@@ -37,7 +39,7 @@ func (m *LampaModule) watchConfigChanges() {
 	})
 
 	// Update
-	m.Ctx.App.OnRecordAfterUpdateSuccess("lampa").BindFunc(func(e *core.RecordEvent) error {
+	m.Ctx.App.OnRecordAfterUpdateSuccess("lampa").BindFunc(func(e *pbCore.RecordEvent) error {
 		if e.Record.Id != m.LampaConfig().Id {
 			return e.Next()
 		}
@@ -49,29 +51,36 @@ func (m *LampaModule) watchConfigChanges() {
 		go m.BuildPassword()
 		go buildInitConfigDebounced()
 
-		return nil
+		// Later handlers, e.g. the config history, must run too
+		return e.Next()
 	})
 
 	// Lampa users collection events
-	m.Ctx.App.OnRecordAfterCreateSuccess("lampa_users").BindFunc(func(e *core.RecordEvent) error {
+	m.Ctx.App.OnRecordAfterCreateSuccess("lampa_users").BindFunc(func(e *pbCore.RecordEvent) error {
 		user := ProxyLampaUser(e.Record)
 		m.Logger.Info("Lampa user created", "UserId", user.UserId())
 		go buildInitConfigDebounced()
 		return e.Next()
 	})
 
-	m.Ctx.App.OnRecordAfterDeleteSuccess("lampa_users").BindFunc(func(e *core.RecordEvent) error {
+	m.Ctx.App.OnRecordAfterDeleteSuccess("lampa_users").BindFunc(func(e *pbCore.RecordEvent) error {
 		user := ProxyLampaUser(e.Record)
 		m.Logger.Info("Lampa user deleted", "UserId", user.UserId())
 		go buildInitConfigDebounced()
 		return e.Next()
 	})
 
-	m.Ctx.App.OnRecordAfterUpdateSuccess("lampa_users").BindFunc(func(e *core.RecordEvent) error {
+	m.Ctx.App.OnRecordAfterUpdateSuccess("lampa_users").BindFunc(func(e *pbCore.RecordEvent) error {
 		user := ProxyLampaUser(e.Record)
 		m.Logger.Info("Lampa user updated", "UserId", user.UserId())
 		go buildInitConfigDebounced()
 		return e.Next()
 	})
 
+	// Access period of the owner changed, accsdb expiry follows it
+	core.Subscribe(m.Ctx, func(event core.AccessExpiryChanged) error {
+		go buildInitConfigDebounced()
+		return nil
+	})
+
 }
@@ -1,14 +1,14 @@
 package lampa
 
 import (
+	"github.com/docker-pet/backend/core"
 	"github.com/docker-pet/backend/models"
-	"github.com/docker-pet/backend/modules/users"
-	"github.com/pocketbase/pocketbase/core"
+	pbCore "github.com/pocketbase/pocketbase/core"
 )
 
 func (m *LampaModule) watchUsersChanges() {
 	// Check all users on app start
-    m.Ctx.App.OnServe().BindFunc(func(e *core.ServeEvent) error {
+	m.Ctx.App.OnServe().BindFunc(func(e *pbCore.ServeEvent) error {
 		users, err := m.users.GetAllUsers()
 		if err != nil {
 			m.Logger.Error("Failed to get all users", "Error", err)
@@ -16,126 +16,69 @@ func (m *LampaModule) watchUsersChanges() {
 		}
 
 		for _, user := range users {
-			lampaUser, err := m.GetLampaUserByUserId(user.Id)
-			needToSave := false
+			m.syncLampaUser(user)
+		}
 
-			// New user
-			if err != nil {
-				if user.Role() == models.RoleGuest {
-					continue
-				}
-				
-				lampaUser, err = m.NewLampaUser(user)
-				needToSave = true
-				if err != nil {
-					m.Logger.Error(
-						"Failed to create Lampa user for existing user",
-						"UserId", user.Id,
-						"Error", err,
-					)
-					continue
-				}
-			}
+		return e.Next()
+	})
 
-			// Has changed role
-			disabled := user.Role() == models.RoleGuest
-			if lampaUser.Disabled() != disabled {
-				lampaUser.SetDisabled(disabled)
-				needToSave = true
-			}
+	// User activated
+	core.Subscribe(m.Ctx, func(event core.UserActivated) error {
+		m.syncLampaUser(event.User)
+		return nil
+	})
 
-			// Has changes
-			if needToSave {
-				if err := m.Ctx.App.Save(lampaUser); err != nil {
-					m.Logger.Error(
-						"Failed to save Lampa user",
-						"UserId", user.Id,
-						"LampaUserId", lampaUser.Id,
-						"Error", err,
-					)
-				}
-			}
+	// User deactivated
+	core.Subscribe(m.Ctx, func(event core.UserDeactivated) error {
+		if event.Deleted {
+			return nil
 		}
 
-        return e.Next()
+		m.syncLampaUser(event.User)
+		return nil
 	})
+}
+
+// syncLampaUser creates the Lampa user for an active user and keeps its
+// disabled flag in line with the user role.
+func (m *LampaModule) syncLampaUser(user *models.User) {
+	lampaUser, err := m.GetLampaUserByUserId(user.Id)
+	needToSave := false
 
-	// User created
-	m.Ctx.App.OnRecordAfterCreateSuccess("users").BindFunc(func(e *core.RecordEvent) error {
-		user := users.ProxyUser(e.Record)
+	// New user
+	if err != nil {
 		if user.Role() == models.RoleGuest {
-			return e.Next()
+			return
 		}
 
-		lampaUser, err := m.NewLampaUser(user)
+		lampaUser, err = m.NewLampaUser(user)
+		needToSave = true
 		if err != nil {
 			m.Logger.Error(
-				"Failed to create Lampa user for new user",
+				"Failed to create Lampa user",
 				"UserId", user.Id,
 				"Error", err,
 			)
+			return
 		}
+	}
+
+	// Has changed role
+	disabled := user.Role() == models.RoleGuest
+	if lampaUser.Disabled() != disabled {
+		lampaUser.SetDisabled(disabled)
+		needToSave = true
+	}
 
+	// Has changes
+	if needToSave {
 		if err := m.Ctx.App.Save(lampaUser); err != nil {
 			m.Logger.Error(
-				"Failed to save Lampa user for new user",
+				"Failed to save Lampa user",
 				"UserId", user.Id,
+				"LampaUserId", lampaUser.Id,
 				"Error", err,
 			)
 		}
-
-		m.Logger.Info(
-			"Created Lampa user for new user",
-			"UserId", user.Id,
-			"LampaUserId", lampaUser.Id,
-		)
-
-		return e.Next()
-	})
-
-	// User updated
-	m.Ctx.App.OnRecordAfterUpdateSuccess("users").BindFunc(func(e *core.RecordEvent) error {
-		user := users.ProxyUser(e.Record)
-		needToSave := false
-		lampaUser, err := m.GetLampaUserByUserId(user.Id)
-
-		// New user
-		if err != nil {
-			if user.Role() == models.RoleGuest {
-				return e.Next()
-			}
-
-			lampaUser, err = m.NewLampaUser(user)
-			needToSave = true
-			if err != nil {
-				m.Logger.Error(
-					"Failed to create Lampa user for updated user",
-					"UserId", user.Id,
-					"Error", err,
-				)
-				return e.Next()
-			}
-		}
-
-		// Has changed role
-		disabled := user.Role() == models.RoleGuest
-		if lampaUser.Disabled() != disabled {
-			lampaUser.SetDisabled(disabled)
-			needToSave = true
-		}
-
-		// Has changes
-		if needToSave {
-			if err := m.Ctx.App.Save(lampaUser); err != nil {
-				m.Logger.Error(
-					"Failed to save Lampa user",
-					"UserId", user.Id,
-					"LampaUserId", lampaUser.Id,
-					"Error", err,
-				)
-			}
-		}
-
-		return e.Next()
-	})
+	}
 }
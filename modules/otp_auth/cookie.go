@@ -4,9 +4,10 @@ import (
 	"net/http"
 	"time"
 
+	"github.com/docker-pet/backend/core"
 	"github.com/docker-pet/backend/models"
 	"github.com/golang-jwt/jwt/v4"
-	"github.com/pocketbase/pocketbase/core"
+	pbCore "github.com/pocketbase/pocketbase/core"
 )
 
 type CookieClaims struct {
@@ -17,7 +18,7 @@ type CookieClaims struct {
 	ValidationDate time.Time       `json:"validationDate"`
 }
 
-func (m *OtpAuthModule) parseCooke(e *core.RequestEvent) *CookieClaims {
+func (m *OtpAuthModule) parseCooke(e *pbCore.RequestEvent) *CookieClaims {
 	claims := &CookieClaims{
 		Pin:        "",
 		UserId:     "",
@@ -31,9 +32,15 @@ func (m *OtpAuthModule) parseCooke(e *core.RequestEvent) *CookieClaims {
 		return claims
 	}
 
-	// Parse JWT token
+	// Parse JWT token, a cookie signed with a retired key is reissued
+	refresh := false
 	token, err := jwt.Parse(cookie.Value, func(token *jwt.Token) (interface{}, error) {
-		return []byte(m.appConfig.AppConfig().AuthSecret()), nil
+		secret, current, err := m.verificationKey(token)
+		if err != nil {
+			return nil, err
+		}
+		refresh = !current
+		return []byte(secret), nil
 	})
 	if err != nil || !token.Valid {
 		return claims
@@ -42,7 +49,7 @@ func (m *OtpAuthModule) parseCooke(e *core.RequestEvent) *CookieClaims {
 	// Extract claims from the token
 	if jwtClaims, ok := token.Claims.(jwt.MapClaims); ok {
 		if v, ok := jwtClaims["pin"].(string); ok {
-			if len(v) == m.appConfig.AppConfig().AuthPinLength() {
+			if m.isValidPinLength(len(v)) {
 				claims.Pin = v
 			}
 		}
@@ -76,7 +83,7 @@ func (m *OtpAuthModule) parseCooke(e *core.RequestEvent) *CookieClaims {
 	if claims.Pin != "" {
 		if found := m.keychain.Exists(claims.Pin); !found {
 			claims.Pin = ""
-			m.Logger.Info("Pin not found", "Pin", claims.Pin)
+			core.Logger(e.Request.Context(), m.Logger).Info("Pin not found", "Pin", claims.Pin)
 		}
 	}
 
@@ -95,17 +102,21 @@ func (m *OtpAuthModule) parseCooke(e *core.RequestEvent) *CookieClaims {
 				claims.UserRole = ""
 				claims.DeviceName = ""
 				claims.ValidationDate = time.Time{}
-				m.Logger.Debug("Unauthenticated user", "UserId", claims.UserId)
+				core.Logger(e.Request.Context(), m.Logger).Debug("Unauthenticated user", "UserId", claims.UserId)
 			}
 
-			m.fillCookie(e, *claims)
+			refresh = true
 		}
 	}
 
+	if refresh {
+		m.fillCookie(e, *claims)
+	}
+
 	return claims
 }
 
-func (m *OtpAuthModule) fillCookie(e *core.RequestEvent, claims CookieClaims) {
+func (m *OtpAuthModule) fillCookie(e *pbCore.RequestEvent, claims CookieClaims) {
 	domain := m.getAppDomain(e)
 	expires := time.Now().Add(10 * 365 * 24 * time.Hour)
 
@@ -116,10 +127,12 @@ func (m *OtpAuthModule) fillCookie(e *core.RequestEvent, claims CookieClaims) {
 		"validationDate": claims.ValidationDate.Format(time.RFC3339),
 		"exp":            expires.Unix(),
 	})
+	secret, kid := m.signingKey()
+	token.Header["kid"] = kid
 
-	tokenStr, err := token.SignedString([]byte(m.appConfig.AppConfig().AuthSecret()))
+	tokenStr, err := token.SignedString([]byte(secret))
 	if err != nil {
-		m.Logger.Error("Failed to sign JWT token", "Err", err)
+		core.Logger(e.Request.Context(), m.Logger).Error("Failed to sign JWT token", "Err", err)
 		return
 	}
 
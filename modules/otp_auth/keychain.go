@@ -10,6 +10,7 @@ import (
 type KeyChainOptions struct {
 	Expiration      time.Duration
 	CleanupInterval time.Duration
+	OnExpired       func(code string) // Called when an unconfirmed code is removed
 }
 
 type KeyChain struct {
@@ -23,8 +24,17 @@ type KeyChainUser struct {
 }
 
 func NewKeyChain(options *KeyChainOptions) *KeyChain {
+	keychain := cache.New(options.Expiration, options.CleanupInterval)
+	if options.OnExpired != nil {
+		keychain.OnEvicted(func(code string, value any) {
+			if user, ok := value.(*KeyChainUser); !ok || user == nil {
+				options.OnExpired(code)
+			}
+		})
+	}
+
 	return &KeyChain{
-		keychain: cache.New(options.Expiration, options.CleanupInterval),
+		keychain: keychain,
 		options:  options,
 	}
 }
@@ -0,0 +1,29 @@
+package otp_auth
+
+import (
+	"github.com/docker-pet/backend/core"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// OTP session events counted by the sessions metric.
+const (
+	sessionCreated   = "created"
+	sessionConfirmed = "confirmed"
+	sessionExpired   = "expired"
+)
+
+func (m *OtpAuthModule) registerMetrics() {
+	m.sessionsMetric = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: core.MetricsNamespace,
+		Subsystem: m.Name(),
+		Name:      "sessions_total",
+		Help:      "OTP sessions by event (created, confirmed, expired).",
+	}, []string{"event"})
+
+	// Pre-create the series so rates work from the first scrape
+	for _, event := range []string{sessionCreated, sessionConfirmed, sessionExpired} {
+		m.sessionsMetric.WithLabelValues(event)
+	}
+
+	m.Ctx.Metrics.MustRegister(m.sessionsMetric)
+}
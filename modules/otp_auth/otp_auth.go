@@ -1,21 +1,41 @@
 package otp_auth
 
 import (
+	"errors"
 	"log/slog"
+	"sync"
 	"time"
 
 	"github.com/docker-pet/backend/core"
 	"github.com/docker-pet/backend/modules/app_config"
 	"github.com/docker-pet/backend/modules/lampa"
+	"github.com/docker-pet/backend/modules/secret_keys"
 	"github.com/docker-pet/backend/modules/users"
+	"github.com/prometheus/client_golang/prometheus"
 )
 
 type Config struct {
-	SessionVerifyInterval             time.Duration // Interval for verifying the authorized OTP session
-	AuthSessionLifetime               time.Duration // Duration after which the OTP session expires
-	ExpiredAuthSessionCleanupInterval time.Duration // Interval at which expired OTP sessions are cleaned up
-	MaxPinGenerationAttempts          int           // Maximum attempts to generate a unique PIN code
+	SessionVerifyInterval             time.Duration `yaml:"sessionVerifyInterval"`             // Interval for verifying the authorized OTP session
+	AuthSessionLifetime               time.Duration `yaml:"authSessionLifetime"`               // Duration after which the OTP session expires
+	ExpiredAuthSessionCleanupInterval time.Duration `yaml:"expiredAuthSessionCleanupInterval"` // Interval at which expired OTP sessions are cleaned up
+	MaxPinGenerationAttempts          int           `yaml:"maxPinGenerationAttempts"`          // Maximum attempts to generate a unique PIN code
+}
 
+func (c *Config) Validate() error {
+	var errs []error
+	if c.SessionVerifyInterval <= 0 {
+		errs = append(errs, errors.New("sessionVerifyInterval must be positive"))
+	}
+	if c.AuthSessionLifetime <= 0 {
+		errs = append(errs, errors.New("authSessionLifetime must be positive"))
+	}
+	if c.ExpiredAuthSessionCleanupInterval <= 0 {
+		errs = append(errs, errors.New("expiredAuthSessionCleanupInterval must be positive"))
+	}
+	if c.MaxPinGenerationAttempts < 1 {
+		errs = append(errs, errors.New("maxPinGenerationAttempts must be at least 1"))
+	}
+	return errors.Join(errs...)
 }
 
 type OtpAuthModule struct {
@@ -23,27 +43,45 @@ type OtpAuthModule struct {
 	Config *Config
 	Logger *slog.Logger
 
-	appConfig *app_config.AppConfigModule
-	users     *users.UsersModule
-	lampa     *lampa.LampaModule
-	keychain  *KeyChain
+	appConfig  *app_config.AppConfigModule
+	users      *users.UsersModule
+	lampa      *lampa.LampaModule
+	secretKeys *secret_keys.SecretKeysModule
+	keychain   *KeyChain
+
+	sessionsMetric *prometheus.CounterVec
+
+	pinLengthMu       sync.RWMutex
+	previousPinLength int       // Pin length before the last authPinLength change
+	pinLengthChanged  time.Time // When authPinLength last changed
 }
 
 func (m *OtpAuthModule) Name() string                  { return "otp_auth" }
 func (m *OtpAuthModule) Deps() []string                { return []string{"users", "app_config"} }
+func (m *OtpAuthModule) OptionalDeps() []string        { return []string{"lampa", "secret_keys"} }
 func (m *OtpAuthModule) SetLogger(logger *slog.Logger) { m.Logger = logger }
 func (m *OtpAuthModule) Init(ctx *core.AppContext, logger *slog.Logger, cfg any) error {
 	m.Ctx = ctx
 	m.Config = cfg.(*Config)
 	m.Logger = logger
-	m.appConfig = m.Ctx.Modules["app_config"].(*app_config.AppConfigModule)
-	m.users = m.Ctx.Modules["users"].(*users.UsersModule)
-	m.lampa = m.Ctx.Modules["lampa"].(*lampa.LampaModule)
+	if err := errors.Join(
+		core.Require(ctx, &m.appConfig),
+		core.Require(ctx, &m.users),
+	); err != nil {
+		return err
+	}
+	core.Lookup(ctx, &m.lampa)
+	core.Lookup(ctx, &m.secretKeys)
+	m.registerMetrics()
 	m.keychain = NewKeyChain(&KeyChainOptions{
 		Expiration:      m.Config.AuthSessionLifetime,
 		CleanupInterval: m.Config.ExpiredAuthSessionCleanupInterval,
+		OnExpired: func(string) {
+			m.sessionsMetric.WithLabelValues(sessionExpired).Inc()
+		},
 	})
 
+	m.watchConfigChanges()
 	m.registerOtpConfirmEndpoint()
 	m.registerOtpVerifyEndpoint()
 	m.registerOtpUserEndpoint()
@@ -4,20 +4,21 @@ import (
 	"net/http"
 
 	"github.com/Jeffail/gabs/v2"
+	"github.com/docker-pet/backend/core"
 	"github.com/docker-pet/backend/helpers"
 	"github.com/docker-pet/backend/models"
 	"github.com/docker-pet/backend/modules/users"
 	"github.com/pocketbase/pocketbase/apis"
-	"github.com/pocketbase/pocketbase/core"
+	pbCore "github.com/pocketbase/pocketbase/core"
 )
 
 func (m *OtpAuthModule) registerOtpConfirmEndpoint() {
-	m.Ctx.App.OnServe().BindFunc(func(se *core.ServeEvent) error {
-		se.Router.POST("/api/otp/confirm", func(e *core.RequestEvent) error {
+	m.Ctx.App.OnServe().BindFunc(func(se *pbCore.ServeEvent) error {
+		se.Router.POST("/api/otp/confirm", func(e *pbCore.RequestEvent) error {
 			// User
 			user := users.ProxyUser(e.Auth)
 			if user.Role() == models.RoleGuest {
-				return e.UnauthorizedError("Guest users are not allowed to confirm OTP", user)
+				return e.UnauthorizedError(m.Ctx.T(e, "otp_auth.guest_forbidden"), user)
 			}
 
 			// Parse JSON body
@@ -29,7 +30,7 @@ func (m *OtpAuthModule) registerOtpConfirmEndpoint() {
 			// Otp code
 			otpCode, ok := data.Path("code").Data().(string)
 			if !ok {
-				return e.BadRequestError("field 'code' must be a string", nil)
+				return e.BadRequestError(m.Ctx.T(e, "otp_auth.code_invalid"), nil)
 			}
 
 			// Not found
@@ -41,6 +42,13 @@ func (m *OtpAuthModule) registerOtpConfirmEndpoint() {
 
 			// Confirm auth
 			m.keychain.Confirm(otpCode, user.Id, user.Role())
+			m.sessionsMetric.WithLabelValues(sessionConfirmed).Inc()
+			m.Ctx.Audit(core.AuditEntry{
+				Module:   m.Name(),
+				Action:   core.AuditOtpConfirmed,
+				TargetId: user.Id,
+				After:    map[string]any{"role": user.Role()},
+			}.WithRequest(e))
 			container := gabs.New()
 			container.Set("confirmed", "notification")
 			return e.JSON(http.StatusOK, container.Data())
@@ -6,9 +6,12 @@ import (
 
 	"github.com/Jeffail/gabs/v2"
 	"github.com/docker-pet/backend/helpers"
+	"github.com/docker-pet/backend/i18n"
 	"github.com/pocketbase/pocketbase/core"
 )
 
+const maxDeviceNameLength = 86
+
 func (m *OtpAuthModule) registerOtpSessionEndpoint() {
 	m.Ctx.App.OnServe().BindFunc(func(se *core.ServeEvent) error {
 		se.Router.POST("/api/otp/session", func(e *core.RequestEvent) error {
@@ -23,8 +26,8 @@ func (m *OtpAuthModule) registerOtpSessionEndpoint() {
 			// Device name is required
 			// TODO: Device name length limit to config file
 			deviceName, ok := data.Path("deviceName").Data().(string)
-			if !ok || len(claims.DeviceName) > 86 {
-				return e.BadRequestError("field 'deviceName' must be a string and not longer than 86 characters", nil)
+			if !ok || len(claims.DeviceName) > maxDeviceNameLength {
+				return e.BadRequestError(m.Ctx.T(e, "otp_auth.device_name_invalid", i18n.Args{"max": maxDeviceNameLength}), nil)
 			}
 			claims.DeviceName = deviceName
 
@@ -42,16 +45,17 @@ func (m *OtpAuthModule) registerOtpSessionEndpoint() {
 				for i := 0; i < m.Config.MaxPinGenerationAttempts; i++ {
 					pin, err := helpers.GeneratePinCode(m.appConfig.AppConfig().AuthPinLength())
 					if err != nil {
-						return e.InternalServerError("Failed to generate PIN code", err)
+						return e.InternalServerError(m.Ctx.T(e, "otp_auth.pin_generation_failed"), err)
 					}
 					if reserved = m.keychain.Reserve(pin); reserved {
 						claims.Pin = pin
+						m.sessionsMetric.WithLabelValues(sessionCreated).Inc()
 						break
 					}
 				}
 
 				if !reserved {
-					return e.InternalServerError("Failed to generate PIN code after multiple attempts", nil)
+					return e.InternalServerError(m.Ctx.T(e, "otp_auth.pin_generation_exhausted", i18n.Args{"count": m.Config.MaxPinGenerationAttempts}), nil)
 				}
 
 				m.fillCookie(e, *claims)
@@ -14,7 +14,7 @@ func (m *OtpAuthModule) registerOtpUserEndpoint() {
 
 			// Unauthenticated user
 			if claims.UserId == "" {
-				return e.UnauthorizedError("You are not authenticated. Please login first.", nil)
+				return e.UnauthorizedError(m.Ctx.T(e, "otp_auth.unauthenticated"), nil)
 			}
 
 			// Response
@@ -25,7 +25,7 @@ func (m *OtpAuthModule) registerOtpUserEndpoint() {
 
 			// Lampa
 			withLampa := e.Request.URL.Query().Has("with-lampa")
-			if withLampa {
+			if withLampa && m.lampa != nil {
 				if lampaUser, _ := m.lampa.GetLampaUserByUserId(claims.UserId); lampaUser != nil {
 					container.Set(lampaUser.AuthKey(), "lampaAuthKey")
 				}
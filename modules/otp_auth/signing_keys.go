@@ -0,0 +1,35 @@
+package otp_auth
+
+import (
+	"errors"
+
+	"github.com/docker-pet/backend/modules/secret_keys"
+	"github.com/golang-jwt/jwt/v4"
+)
+
+// signingKey returns the authSecret new cookies are signed with and its
+// key id.
+func (m *OtpAuthModule) signingKey() (string, string) {
+	secret := m.appConfig.AppConfig().AuthSecret()
+	return secret, secret_keys.KeyId(secret)
+}
+
+// verificationKey resolves the secret a cookie was signed with. Cookies
+// issued before key ids existed carry none and are checked against the
+// current secret. current is false for a retired secret still in its grace
+// period.
+func (m *OtpAuthModule) verificationKey(token *jwt.Token) (secret string, current bool, err error) {
+	currentSecret, currentKid := m.signingKey()
+	kid, _ := token.Header["kid"].(string)
+	if kid == "" || kid == currentKid {
+		return currentSecret, true, nil
+	}
+
+	if m.secretKeys != nil {
+		appConfig := m.appConfig.AppConfig()
+		if secret, ok := m.secretKeys.Resolve(secret_keys.ScopeAuthSecret, appConfig.Id, currentSecret, kid); ok {
+			return secret, false, nil
+		}
+	}
+	return "", false, errors.New("unknown signing key " + kid)
+}
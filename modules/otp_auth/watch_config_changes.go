@@ -0,0 +1,44 @@
+package otp_auth
+
+import (
+	"time"
+
+	"github.com/docker-pet/backend/core"
+)
+
+// watchConfigChanges keeps pins of the previous length valid for one
+// session lifetime after authPinLength changes, so sessions started before
+// the change can still be confirmed.
+func (m *OtpAuthModule) watchConfigChanges() {
+	core.Subscribe(m.Ctx, func(event core.AppConfigChanged) error {
+		if !event.Changed("authPinLength") {
+			return nil
+		}
+
+		m.pinLengthMu.Lock()
+		m.previousPinLength = event.Old.AuthPinLength()
+		m.pinLengthChanged = time.Now()
+		m.pinLengthMu.Unlock()
+
+		m.Logger.Info(
+			"OTP pin length changed",
+			"Old", event.Old.AuthPinLength(),
+			"New", event.New.AuthPinLength(),
+		)
+		return nil
+	})
+}
+
+// isValidPinLength reports whether a pin of the given length can belong to
+// a live session.
+func (m *OtpAuthModule) isValidPinLength(length int) bool {
+	if length == m.appConfig.AppConfig().AuthPinLength() {
+		return true
+	}
+
+	m.pinLengthMu.RLock()
+	defer m.pinLengthMu.RUnlock()
+
+	return length == m.previousPinLength &&
+		time.Since(m.pinLengthChanged) < m.Config.AuthSessionLifetime
+}
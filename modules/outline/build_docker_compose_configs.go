@@ -175,7 +175,7 @@ func (m *OutlineModule) buildDockerComposeConfigs() {
 		// Create config
 		root := &yaml.Node{
 			Kind:        yaml.DocumentNode,
-			HeadComment: "This file was generated automatically.\nPlease use it as a template for running Outline VPN Server.",
+			HeadComment: m.Ctx.I18n.T(m.Ctx.I18n.FallbackLocale(), "outline.generated.docker_compose"),
 			Content: []*yaml.Node{
 				{
 					Kind: yaml.MappingNode,
@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"path/filepath"
 
+	"github.com/docker-pet/backend/core"
 	"github.com/docker-pet/backend/helpers"
 	"gopkg.in/yaml.v3"
 )
@@ -47,7 +48,7 @@ func (m *OutlineModule) buildPrometheusConfig() bool {
 			{
 				Kind:        yaml.ScalarNode,
 				Value:       m.Config.PrometheusJobName,
-				LineComment: "This job is auto-managed; manual edits will be overwritten.",
+				LineComment: m.Ctx.I18n.T(m.Ctx.I18n.FallbackLocale(), "outline.generated.managed_job"),
 			},
 			{
 				Kind:  yaml.ScalarNode,
@@ -101,26 +102,8 @@ func (m *OutlineModule) buildPrometheusConfig() bool {
 		}, scrapeConfigsNode)
 	}
 
-	// Search and replace or add the job block
-	replaced := false
-	for idx, sc := range scrapeConfigsNode.Content {
-		if sc.Kind == yaml.MappingNode {
-			for i := 0; i < len(sc.Content); i += 2 {
-				k, v := sc.Content[i], sc.Content[i+1]
-				if k.Value == "job_name" && v.Value == m.Config.PrometheusJobName {
-					scrapeConfigsNode.Content[idx] = jobNode
-					replaced = true
-					break
-				}
-			}
-		}
-		if replaced {
-			break
-		}
-	}
-	if !replaced {
-		scrapeConfigsNode.Content = append(scrapeConfigsNode.Content, jobNode)
-	}
+	upsertScrapeJob(scrapeConfigsNode, m.Config.PrometheusJobName, jobNode)
+	upsertScrapeJob(scrapeConfigsNode, m.Config.PrometheusBackendJobName, m.backendScrapeJob())
 
 	// Create folders
 	err := helpers.EnsureDir(prometheusTargetsPath)
@@ -150,6 +133,69 @@ func (m *OutlineModule) buildPrometheusConfig() bool {
 	return updated
 }
 
+// backendScrapeJob scrapes the backend's own metrics endpoint.
+func (m *OutlineModule) backendScrapeJob() *yaml.Node {
+	return &yaml.Node{
+		Kind: yaml.MappingNode,
+		Content: []*yaml.Node{
+			{Kind: yaml.ScalarNode, Value: "job_name"},
+			{
+				Kind:        yaml.ScalarNode,
+				Value:       m.Config.PrometheusBackendJobName,
+				LineComment: m.Ctx.I18n.T(m.Ctx.I18n.FallbackLocale(), "outline.generated.managed_job"),
+			},
+			{Kind: yaml.ScalarNode, Value: "scheme"},
+			{Kind: yaml.ScalarNode, Value: "https"},
+			{Kind: yaml.ScalarNode, Value: "metrics_path"},
+			{Kind: yaml.ScalarNode, Value: core.MetricsPath},
+			{Kind: yaml.ScalarNode, Value: "authorization"},
+			{
+				Kind: yaml.MappingNode,
+				Content: []*yaml.Node{
+					{Kind: yaml.ScalarNode, Value: "credentials"},
+					{Kind: yaml.ScalarNode, Value: m.Ctx.MetricsSecret},
+				},
+			},
+			{Kind: yaml.ScalarNode, Value: "static_configs"},
+			{
+				Kind: yaml.SequenceNode,
+				Content: []*yaml.Node{
+					{
+						Kind: yaml.MappingNode,
+						Content: []*yaml.Node{
+							{Kind: yaml.ScalarNode, Value: "targets"},
+							{
+								Kind: yaml.SequenceNode,
+								Content: []*yaml.Node{
+									{Kind: yaml.ScalarNode, Value: m.appConfig.AppConfig().AppDomain()},
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+// upsertScrapeJob replaces the scrape config with the given job name or
+// appends it.
+func upsertScrapeJob(scrapeConfigsNode *yaml.Node, jobName string, jobNode *yaml.Node) {
+	for idx, sc := range scrapeConfigsNode.Content {
+		if sc.Kind != yaml.MappingNode {
+			continue
+		}
+		for i := 0; i < len(sc.Content); i += 2 {
+			k, v := sc.Content[i], sc.Content[i+1]
+			if k.Value == "job_name" && v.Value == jobName {
+				scrapeConfigsNode.Content[idx] = jobNode
+				return
+			}
+		}
+	}
+	scrapeConfigsNode.Content = append(scrapeConfigsNode.Content, jobNode)
+}
+
 func (m *OutlineModule) buildPrometheusTargets() {
 	prometheusTargetsPath := filepath.Join(m.Config.PrometheusStoragePath, m.getPrometheusTargetsRelativePath())
 	servers, err := m.GetAllServers()
@@ -181,7 +227,7 @@ func (m *OutlineModule) buildPrometheusTargets() {
 		// Create config
 		targetsNode := &yaml.Node{
 			Kind:        yaml.SequenceNode,
-			HeadComment: "This file is auto-managed; manual edits will be overwritten.",
+			HeadComment: m.Ctx.I18n.T(m.Ctx.I18n.FallbackLocale(), "outline.generated.managed_file"),
 			Content: []*yaml.Node{
 				{
 					Kind: yaml.MappingNode,
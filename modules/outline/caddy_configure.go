@@ -1,13 +1,16 @@
 package outline
 
 import (
+	"context"
 	"fmt"
 	"os"
 	"path"
 	"path/filepath"
 	"strings"
+	"time"
 
 	"github.com/Jeffail/gabs/v2"
+	"github.com/docker-pet/backend/core"
 	"github.com/docker-pet/backend/helpers"
 	"github.com/docker-pet/backend/models"
 	"github.com/pocketbase/pocketbase/tools/security"
@@ -20,10 +23,10 @@ type Token struct {
 	Token  string
 }
 
-func (m *OutlineModule) configureAll() {
+func (m *OutlineModule) configureAll(ctx context.Context) {
 	servers, err := m.GetAllActiveServers()
 	if err != nil {
-		m.Logger.Warn(
+		core.Logger(ctx, m.Logger).Warn(
 			"Failed to get all active Outline servers",
 			"Error", err,
 		)
@@ -31,33 +34,53 @@ func (m *OutlineModule) configureAll() {
 	}
 
 	for _, server := range servers {
-		m.configureCaddy(server.Id)
+		m.configureCaddy(ctx, server.Id)
 	}
 }
 
-func (m *OutlineModule) configureCaddy(serverId string) {
-	// Tokens
-	var tokens []*Token
-	if users, _ := m.users.GetAllUsers(); users != nil {
-		for _, user := range users {
-			if user.OutlineToken() != "" && user.IsActive() {
-				tokens = append(tokens, &Token{
-					UserId: user.Id,
-					Token:  user.OutlineToken(),
-				})
-			}
-		}
+func (m *OutlineModule) configureCaddy(ctx context.Context, serverId string) error {
+	err := m.syncCaddy(ctx, serverId)
+	m.caddySync.record(serverId, err)
+
+	m.metrics.caddyConfigures.WithLabelValues(serverId).Inc()
+	if err != nil {
+		m.metrics.caddyConfigureFailures.WithLabelValues(serverId).Inc()
 	}
+	return err
+}
+
+// ResyncServer configures the Caddy of the server right away instead of
+// waiting for its sync cron.
+func (m *OutlineModule) ResyncServer(ctx context.Context, serverId string) error {
+	return m.configureCaddy(ctx, serverId)
+}
+
+func (m *OutlineModule) syncCaddy(ctx context.Context, serverId string) error {
+	logger := core.Logger(ctx, m.Logger)
 
 	server, err := m.GetServerById(serverId)
 	hasServerChanges := false
 	if err != nil {
-		m.Logger.Warn(
+		logger.Warn(
 			"Failed to get server by ID",
 			"Error", err,
 			"ServerId", serverId,
 		)
-		return
+		return err
+	}
+
+	// Tokens, premium servers only accept users with active premium
+	var tokens []*Token
+	now := time.Now()
+	if users, _ := m.users.GetAllUsers(); users != nil {
+		for _, user := range users {
+			if user.OutlineToken() != "" && user.IsActive() && (!server.Premium() || user.EffectivePremium(now)) {
+				tokens = append(tokens, &Token{
+					UserId: user.Id,
+					Token:  user.OutlineToken(),
+				})
+			}
+		}
 	}
 
 	configureJustLocalFile := server.SyncType() == models.OutlineLocalSync
@@ -68,55 +91,60 @@ func (m *OutlineModule) configureCaddy(serverId string) {
 		rawConfig, hasServerChanges, err = m.getLocalCaddyConfig(server)
 	}
 
+	var remoteErr error
 	if err != nil {
-		m.Logger.Warn(
+		logger.Warn(
 			"Failed to get Caddy configuration",
 			"Reason", err.Error(),
 		)
 
 		if server.SyncType() == models.OutlineRemoteSync {
+			remoteErr = err
 			configureJustLocalFile = true
 			rawConfig, hasServerChanges = m.GenerateBasicCaddyConfig(server)
 
-			m.Logger.Info(
+			logger.Info(
 				"Using basic Caddy configuration",
 			)
 		} else {
-			return
+			return err
 		}
 	}
 
 	// Need to save server changes & stop configure
 	if hasServerChanges {
-		m.Logger.Info(
+		logger.Info(
 			"Server configuration has changes, stopping Caddy configuration",
 			"ServerId", server.Id,
 			"ServerSlug", server.Slug(),
 		)
 		err = m.Ctx.App.Save(server)
 		if err != nil {
-			m.Logger.Warn(
+			logger.Warn(
 				"Failed to save server changes",
 				"Error", err,
 				"ServerId", server.Id,
 				"ServerSlug", server.Slug(),
 			)
 		}
-		return
+		return err
 	}
 
 	// Parse the Caddy configuration
 	config, err := gabs.ParseJSON(rawConfig)
 	if err != nil {
-		println(string(rawConfig))
-
-		m.Logger.Warn(
+		logger.Warn(
 			"Failed to parse Caddy configuration",
 			"Error", err,
 			"ServerId", server.Id,
 			"SyncType", server.SyncType(),
 		)
-		return
+		logger.Debug(
+			"Unparsable Caddy configuration",
+			"ServerId", server.Id,
+			"RawConfig", string(rawConfig),
+		)
+		return err
 	}
 
 	needToSave := m.hasKeysChanges(config, tokens)
@@ -124,25 +152,25 @@ func (m *OutlineModule) configureCaddy(serverId string) {
 	// clear websocket2layer4 routes
 	err = removeWebsocket2Layer4Routes(config)
 	if err != nil {
-		m.Logger.Warn(
+		logger.Warn(
 			"Failed to remove websocket2layer4 routes",
 			"Error", err,
 			"ServerId", server.Id,
 			"Host", m.formatJobDomain(server),
 		)
-		return
+		return err
 	}
 
 	// find server key
 	serverKey, err := findServerKey(config, server)
 	if err != nil {
-		m.Logger.Warn(
+		logger.Warn(
 			"Failed to find server key in Caddy configuration",
 			"Error", err,
 			"ServerId", server.Id,
 			"Host", m.formatJobDomain(server),
 		)
-		return
+		return err
 	}
 
 	// websocket2layer4
@@ -150,29 +178,29 @@ func (m *OutlineModule) configureCaddy(serverId string) {
 		m.generateWebsocket2layer4Route(server, "tcp"),
 		m.generateWebsocket2layer4Route(server, "udp"),
 	); err != nil {
-		m.Logger.Warn(
+		logger.Warn(
 			"Failed to prepend websocket2layer4 routes",
 			"Error", err,
 			"ServerId", server.Id,
 			"Host", m.formatJobDomain(server),
 		)
-		return
+		return err
 	}
 
 	// No changes needed
 	if !needToSave {
-		m.Logger.Info(
+		logger.Info(
 			"No changes needed for Outline configuration",
 			"ServerId", server.Id,
 			"TokensCount", len(tokens),
 		)
-		return
+		return remoteErr
 	}
 
 	// Outline config
 	config.SetP(m.generateOutlineConfig(server, tokens), "apps.outline")
 
-	m.Logger.Info(
+	logger.Info(
 		"Saving Outline configuration",
 		"ServerId", server.Id,
 		"ServerSlug", server.Slug(),
@@ -189,21 +217,26 @@ func (m *OutlineModule) configureCaddy(serverId string) {
 	)
 
 	// Save remote Caddy configuration
-	if server.SyncType() == models.OutlineRemoteSync && !configureJustLocalFile {
+	if err == nil && server.SyncType() == models.OutlineRemoteSync && !configureJustLocalFile {
 		err = m.saveRemoteCaddyConfig(server, config.String())
 	}
 
 	if err != nil {
-		m.Logger.Warn(
+		logger.Warn(
 			"Failed to save Caddy configuration",
 			"Error", err,
 			"ServerId", server.Id,
 			"ServerSlug", server.Slug(),
 		)
+		return err
 	}
+
+	return remoteErr
 }
 
-func (m *OutlineModule) CreateCaddyRequest(server *models.OutlineServer, command string) (*resty.Request, string) {
+// CreateCaddyRequest prepares a request to the Caddy admin API of a remote
+// server using the given HTTP client profile.
+func (m *OutlineModule) CreateCaddyRequest(server *models.OutlineServer, profile string, command string) (*resty.Request, string) {
 	config := server.SyncRemoteConfig()
 
 	endpoint := config.RemoteAdminEndpoint
@@ -220,7 +253,7 @@ func (m *OutlineModule) CreateCaddyRequest(server *models.OutlineServer, command
 
 	requestUrl := fmt.Sprintf("%s/%s", strings.TrimRight(endpoint, "/"), strings.TrimLeft(command, "/"))
 
-	request := m.Ctx.HttpClient.R()
+	request := m.Ctx.HttpClients.Client(profile).R()
 	if config.RemoteAdminBasicAuth != nil && config.RemoteAdminBasicAuth.Username != "" {
 		request = request.SetBasicAuth(
 			config.RemoteAdminBasicAuth.Username,
@@ -232,7 +265,7 @@ func (m *OutlineModule) CreateCaddyRequest(server *models.OutlineServer, command
 }
 
 func (m *OutlineModule) getRemoteCaddyConfig(server *models.OutlineServer) ([]byte, error) {
-	request, requestUrl := m.CreateCaddyRequest(server, "config")
+	request, requestUrl := m.CreateCaddyRequest(server, core.HttpProfileCaddyAdmin, "config")
 	response, err := request.Get(requestUrl)
 	if err != nil {
 		return nil, fmt.Errorf("failed to fetch remote Caddy config: %w", err)
@@ -246,12 +279,20 @@ func (m *OutlineModule) getRemoteCaddyConfig(server *models.OutlineServer) ([]by
 }
 
 func (m *OutlineModule) saveRemoteCaddyConfig(server *models.OutlineServer, config string) error {
-	request, requestUrl := m.CreateCaddyRequest(server, "config")
-	_, err := request.
+	request, requestUrl := m.CreateCaddyRequest(server, core.HttpProfileCaddyAdmin, "config")
+	response, err := request.
 		SetContentType("application/json").
 		SetBody(config).
 		Patch(requestUrl)
-	return err
+	if err != nil {
+		return fmt.Errorf("failed to save remote Caddy config: %w", err)
+	}
+
+	if response.IsError() {
+		return fmt.Errorf("failed to save remote Caddy config: %s", response.Status())
+	}
+
+	return nil
 }
 
 func (m *OutlineModule) getLocalCaddyConfig(server *models.OutlineServer) ([]byte, bool, error) {
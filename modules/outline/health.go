@@ -0,0 +1,93 @@
+package outline
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/docker-pet/backend/helpers"
+)
+
+type CaddySyncStatus struct {
+	LastAttempt time.Time
+	LastSuccess time.Time
+	LastError   error
+}
+
+type caddySyncState struct {
+	mu      sync.RWMutex
+	servers map[string]*CaddySyncStatus
+}
+
+func (s *caddySyncState) record(serverId string, err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.servers == nil {
+		s.servers = map[string]*CaddySyncStatus{}
+	}
+
+	status, ok := s.servers[serverId]
+	if !ok {
+		status = &CaddySyncStatus{}
+		s.servers[serverId] = status
+	}
+
+	status.LastAttempt = time.Now()
+	status.LastError = err
+	if err == nil {
+		status.LastSuccess = status.LastAttempt
+	}
+}
+
+func (s *caddySyncState) get(serverId string) (CaddySyncStatus, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	status, ok := s.servers[serverId]
+	if !ok {
+		return CaddySyncStatus{}, false
+	}
+	return *status, true
+}
+
+// CaddySyncStatus returns the result of the latest Caddy sync of the server.
+func (m *OutlineModule) CaddySyncStatus(serverId string) (CaddySyncStatus, bool) {
+	return m.caddySync.get(serverId)
+}
+
+func (m *OutlineModule) HealthCheck(ctx context.Context) error {
+	var errs []error
+
+	for _, path := range []string{m.Config.OutlineStoragePath, m.Config.PrometheusStoragePath} {
+		if err := helpers.CheckWritable(path); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	servers, err := m.GetAllActiveServers()
+	if err != nil {
+		return errors.Join(append(errs, fmt.Errorf("failed to get outline servers: %w", err))...)
+	}
+
+	for _, server := range servers {
+		status, ok := m.caddySync.get(server.Id)
+		switch {
+		case !ok:
+			errs = append(errs, fmt.Errorf("server %s: caddy was not synced yet", server.Slug()))
+		case status.LastError != nil && status.LastSuccess.IsZero():
+			errs = append(errs, fmt.Errorf("server %s: caddy sync failed, never succeeded: %w", server.Slug(), status.LastError))
+		case status.LastError != nil:
+			errs = append(errs, fmt.Errorf(
+				"server %s: caddy sync failed, last success at %s: %w",
+				server.Slug(),
+				status.LastSuccess.UTC().Format(time.RFC3339),
+				status.LastError,
+			))
+		}
+	}
+
+	return errors.Join(errs...)
+}
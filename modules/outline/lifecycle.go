@@ -0,0 +1,24 @@
+package outline
+
+import (
+	"context"
+	"strings"
+
+	"github.com/docker-pet/backend/helpers"
+)
+
+func (m *OutlineModule) Stop(ctx context.Context) error {
+	// Remove Caddy sync cron jobs
+	for _, job := range m.Ctx.App.Cron().Jobs() {
+		if strings.HasPrefix(job.Id(), cronJobPrefix) {
+			m.Ctx.App.Cron().Remove(job.Id())
+		}
+	}
+
+	// Push pending key changes before shutting down
+	if m.configureAllControl.Pending != nil && m.configureAllControl.Pending() {
+		return helpers.WaitContext(ctx, m.configureAllControl.Flush)
+	}
+
+	return nil
+}
@@ -0,0 +1,30 @@
+package outline
+
+import (
+	"github.com/docker-pet/backend/core"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+type outlineMetrics struct {
+	caddyConfigures        *prometheus.CounterVec
+	caddyConfigureFailures *prometheus.CounterVec
+}
+
+func (m *OutlineModule) registerMetrics() {
+	m.metrics = &outlineMetrics{
+		caddyConfigures: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: core.MetricsNamespace,
+			Subsystem: m.Name(),
+			Name:      "caddy_configure_attempts_total",
+			Help:      "Caddy configuration attempts per server.",
+		}, []string{"server_id"}),
+		caddyConfigureFailures: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: core.MetricsNamespace,
+			Subsystem: m.Name(),
+			Name:      "caddy_configure_failures_total",
+			Help:      "Failed Caddy configuration attempts per server.",
+		}, []string{"server_id"}),
+	}
+
+	m.Ctx.Metrics.MustRegister(m.metrics.caddyConfigures, m.metrics.caddyConfigureFailures)
+}
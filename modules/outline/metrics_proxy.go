@@ -2,26 +2,38 @@ package outline
 
 import (
 	"context"
+	"crypto/subtle"
 	"io"
 	"net"
 	"net/http"
 	"path"
 	"path/filepath"
 
+	"github.com/docker-pet/backend/core"
 	"github.com/docker-pet/backend/models"
-	"github.com/pocketbase/pocketbase/core"
+	"github.com/docker-pet/backend/modules/secret_keys"
+	pbCore "github.com/pocketbase/pocketbase/core"
 	"resty.dev/v3"
 )
 
+// acceptsMetricsSecret also accepts a rotated secret during its grace
+// period, Prometheus keeps scraping with it until the config is reloaded.
+func (m *OutlineModule) acceptsMetricsSecret(server *models.OutlineServer, secret string) bool {
+	if m.secretKeys == nil {
+		return subtle.ConstantTimeCompare([]byte(server.MetricsSecret()), []byte(secret)) == 1
+	}
+	return m.secretKeys.Accepts(secret_keys.ScopeOutlineMetricsSecret, server.Id, server.MetricsSecret(), secret)
+}
+
 func (m *OutlineModule) registerMetrixProxyEndpoint() {
-	m.Ctx.App.OnServe().BindFunc(func(se *core.ServeEvent) error {
-		se.Router.GET("/api/outline/metics/{serverId}/{metricsSecret}", func(e *core.RequestEvent) error {
+	m.Ctx.App.OnServe().BindFunc(func(se *pbCore.ServeEvent) error {
+		se.Router.GET("/api/outline/metics/{serverId}/{metricsSecret}", func(e *pbCore.RequestEvent) error {
 			// Get server
 			serverId := e.Request.PathValue("serverId")
 			metricsSecret := e.Request.PathValue("metricsSecret")
 			server, err := m.GetServerById(serverId)
 
-			if err != nil || server.MetricsSecret() != metricsSecret {
+			if err != nil || !m.acceptsMetricsSecret(server, metricsSecret) {
 				return e.NotFoundError(
 					"Server not found",
 					"The server with the specified ID does not exist or the metrics secret is invalid.",
@@ -39,7 +51,7 @@ func (m *OutlineModule) registerMetrixProxyEndpoint() {
 
 			// Request (Remote Sync)
 			if server.SyncType() == models.OutlineRemoteSync {
-				request, requestUrl := m.CreateCaddyRequest(server, "metrics")
+				request, requestUrl := m.CreateCaddyRequest(server, core.HttpProfileMetrics, "metrics")
 				response, err = request.Get(requestUrl)
 			} else if server.SyncType() == models.OutlineLocalSync {
 				transport := &http.Transport{
@@ -48,11 +60,12 @@ func (m *OutlineModule) registerMetrixProxyEndpoint() {
 					},
 				}
 
-				client := resty.New().
-					SetTransport(transport).
-					SetBaseURL("http://unix")
-
-				response, err = client.R().Get(path.Join("/", "metrics"))
+				var client *resty.Client
+				client, err = m.Ctx.HttpClients.NewClient(core.HttpProfileMetrics, transport)
+				if err == nil {
+					defer client.Close()
+					response, err = client.SetBaseURL("http://unix").R().Get(path.Join("/", "metrics"))
+				}
 			}
 
 			if err != nil {
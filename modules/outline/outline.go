@@ -1,27 +1,63 @@
 package outline
 
 import (
+	"errors"
+	"fmt"
 	"log/slog"
+	"slices"
 
 	"github.com/docker-pet/backend/core"
 	"github.com/docker-pet/backend/modules/app_config"
+	"github.com/docker-pet/backend/modules/secret_keys"
 	"github.com/docker-pet/backend/modules/users"
 	"github.com/pocketbase/pocketbase/tools/security"
+	"github.com/zmwangx/debounce"
 )
 
 type Config struct {
-	OutlineStoragePath        string
-	OutlineCipher             string
-	OutlineTechnicalKeyName   string
-	OutlineTechnicalKeySecret string
+	OutlineStoragePath        string `yaml:"outlineStoragePath"`
+	OutlineCipher             string `yaml:"outlineCipher"`
+	OutlineTechnicalKeyName   string `yaml:"outlineTechnicalKeyName"`
+	OutlineTechnicalKeySecret string `yaml:"outlineTechnicalKeySecret"`
 
-	PrometheusStoragePath       string
-	PrometheusJobName           string
-	PrometheusJobManagedByLabel string
+	PrometheusStoragePath       string `yaml:"prometheusStoragePath"`
+	PrometheusJobName           string `yaml:"prometheusJobName"`
+	PrometheusJobManagedByLabel string `yaml:"prometheusJobManagedByLabel"`
+	PrometheusBackendJobName    string `yaml:"prometheusBackendJobName"` // Scrape job for the backend's own /metrics
 
-	CaddyCloudflareApiToken string // Is the API token for Cloudflare. If not set, will use a placeholder.
+	CaddyCloudflareApiToken string `yaml:"caddyCloudflareApiToken"` // Is the API token for Cloudflare. If not set, will use a placeholder.
 
-	MetricsProxySecret string // Is the secret for the metrics proxy endpoint. If not set, a new one will be generated (recommended).
+	MetricsProxySecret string `yaml:"metricsProxySecret"` // Is the secret for the metrics proxy endpoint. If not set, a new one will be generated (recommended).
+}
+
+var supportedCiphers = []string{
+	"chacha20-ietf-poly1305",
+	"aes-128-gcm",
+	"aes-192-gcm",
+	"aes-256-gcm",
+}
+
+func (c *Config) Validate() error {
+	var errs []error
+	if c.OutlineStoragePath == "" {
+		errs = append(errs, errors.New("outlineStoragePath is required"))
+	}
+	if !slices.Contains(supportedCiphers, c.OutlineCipher) {
+		errs = append(errs, fmt.Errorf("outlineCipher must be one of %v", supportedCiphers))
+	}
+	if c.OutlineTechnicalKeyName == "" {
+		errs = append(errs, errors.New("outlineTechnicalKeyName is required"))
+	}
+	if c.PrometheusStoragePath == "" {
+		errs = append(errs, errors.New("prometheusStoragePath is required"))
+	}
+	if c.PrometheusJobName == "" {
+		errs = append(errs, errors.New("prometheusJobName is required"))
+	}
+	if c.PrometheusBackendJobName == "" || c.PrometheusBackendJobName == c.PrometheusJobName {
+		errs = append(errs, errors.New("prometheusBackendJobName is required and must differ from prometheusJobName"))
+	}
+	return errors.Join(errs...)
 }
 
 type OutlineModule struct {
@@ -29,25 +65,37 @@ type OutlineModule struct {
 	Config *Config
 	Logger *slog.Logger
 
-	users     *users.UsersModule
-	appConfig *app_config.AppConfigModule
+	users      *users.UsersModule
+	appConfig  *app_config.AppConfigModule
+	secretKeys *secret_keys.SecretKeysModule
+
+	configureAllControl debounce.Control
+	caddySync           caddySyncState
+	metrics             *outlineMetrics
 }
 
 func (m *OutlineModule) Name() string                  { return "outline" }
 func (m *OutlineModule) Deps() []string                { return []string{"users", "app_config"} }
+func (m *OutlineModule) OptionalDeps() []string        { return []string{"secret_keys"} }
 func (m *OutlineModule) SetLogger(logger *slog.Logger) { m.Logger = logger }
 func (m *OutlineModule) Init(ctx *core.AppContext, logger *slog.Logger, cfg any) error {
 	m.Ctx = ctx
 	m.Config = cfg.(*Config)
 	m.Logger = logger
-	m.users = m.Ctx.Modules["users"].(*users.UsersModule)
-	m.appConfig = m.Ctx.Modules["app_config"].(*app_config.AppConfigModule)
+	if err := errors.Join(
+		core.Require(ctx, &m.users),
+		core.Require(ctx, &m.appConfig),
+	); err != nil {
+		return err
+	}
+	core.Lookup(ctx, &m.secretKeys)
 
 	// Generate metrics proxy secret if not set
 	if m.Config.MetricsProxySecret == "" {
 		m.Config.MetricsProxySecret = security.RandomString(32)
 	}
 
+	m.registerMetrics()
 	m.registerMetrixProxyEndpoint()
 	m.registerOutlineConnectEndpoint()
 	m.registerSettingsEndpoint()
@@ -10,20 +10,32 @@ import (
 	"strings"
 	"time"
 
+	"github.com/docker-pet/backend/core"
 	"github.com/docker-pet/backend/models"
-	"github.com/pocketbase/pocketbase/core"
+	pbCore "github.com/pocketbase/pocketbase/core"
 	"gopkg.in/yaml.v3"
 )
 
 func (m *OutlineModule) registerOutlineConnectEndpoint() {
-	sendError := func(e *core.RequestEvent, message string, details string) error {
-		m.Logger.Error(message, "Details", details)
+	// Errors are returned as a config with an error section, the Outline
+	// client shows them to the user
+	sendError := func(e *pbCore.RequestEvent, locale string, key string) error {
+		core.Logger(e.Request.Context(), m.Logger).Error("Failed to build Outline connect config", "Error", key)
+		content, err := yaml.Marshal(map[string]any{
+			"error": map[string]string{
+				"message": m.Ctx.I18n.T(locale, key),
+				"details": m.Ctx.I18n.T(locale, key+".details"),
+			},
+		})
+		if err != nil {
+			return err
+		}
 		e.Response.Header().Set("Cache-Control", "no-cache, no-store, must-revalidate")
-		return e.Blob(http.StatusOK, "application/x-yaml", []byte("error:\n  message: "+message+"\n  details: "+details))
+		return e.Blob(http.StatusOK, "application/x-yaml", content)
 	}
 
-	m.Ctx.App.OnServe().BindFunc(func(se *core.ServeEvent) error {
-		se.Router.GET("/api/outline/{userId}/{outlineSecret}", func(e *core.RequestEvent) error {
+	m.Ctx.App.OnServe().BindFunc(func(se *pbCore.ServeEvent) error {
+		se.Router.GET("/api/outline/{userId}/{outlineSecret}", func(e *pbCore.RequestEvent) error {
 			userId := e.Request.PathValue("userId")
 			outlineSecret := e.Request.PathValue("outlineSecret")
 
@@ -38,8 +50,8 @@ func (m *OutlineModule) registerOutlineConnectEndpoint() {
 			if err != nil {
 				return sendError(
 					e,
-					"User not found",
-					"The user with the specified ID does not exist.",
+					m.Ctx.RequestLocale(e),
+					"outline.connect.user_not_found",
 				)
 			}
 
@@ -47,8 +59,8 @@ func (m *OutlineModule) registerOutlineConnectEndpoint() {
 			if user.OutlineToken() != outlineSecret {
 				return sendError(
 					e,
-					"Invalid Outline Secret",
-					"The provided Outline secret is invalid or does not match the user's secret.",
+					user.Language(),
+					"outline.connect.invalid_secret",
 				)
 			}
 
@@ -56,34 +68,26 @@ func (m *OutlineModule) registerOutlineConnectEndpoint() {
 			if !user.IsActive() {
 				return sendError(
 					e,
-					"Guest Access Denied",
-					"Guests are not allowed to connect to Outline.",
+					user.Language(),
+					"outline.connect.guest_denied",
 				)
 			}
 
-			servers, err := m.GetAllActiveServers()
+			// Available servers
+			availableServers, err := m.AvailableServers(user)
 			if err != nil {
 				return sendError(
 					e,
-					"Failed to retrieve Outline servers",
-					"An error occurred while trying to retrieve the list of active Outline servers.",
+					user.Language(),
+					"outline.connect.servers_failed",
 				)
 			}
 
-			// Available servers
-			availableServers := make([]*models.OutlineServer, 0)
-			for _, server := range servers {
-				if !user.Premium() && server.Premium() {
-					continue
-				}
-				availableServers = append(availableServers, server)
-			}
-
-			if len(servers) == 0 {
+			if len(availableServers) == 0 {
 				return sendError(
 					e,
-					"No Active Outline Servers",
-					"There are currently no active Outline servers available for connection.",
+					user.Language(),
+					"outline.connect.no_servers",
 				)
 			}
 
@@ -209,8 +213,8 @@ func (m *OutlineModule) registerOutlineConnectEndpoint() {
 			if err != nil {
 				return sendError(
 					e,
-					"Config Generation Error",
-					"An error occurred while generating the Outline configuration.",
+					user.Language(),
+					"outline.connect.config_failed",
 				)
 			}
 
@@ -222,21 +226,49 @@ func (m *OutlineModule) registerOutlineConnectEndpoint() {
 			return e.Blob(http.StatusOK, "application/x-yaml", content)
 		})
 
-		se.Router.GET("/api/outline/redirect/{userId}/{outlineSecret}", func(e *core.RequestEvent) error {
-			url := fmt.Sprintf(
-				"ssconf://%s/api/outline/%s/%s#%s",
-				m.appConfig.AppConfig().AppDomain(),
-				e.Request.PathValue("userId"),
-				e.Request.PathValue("outlineSecret"),
-				url.PathEscape(m.appConfig.AppConfig().AppTitle()),
+		se.Router.GET("/api/outline/redirect/{userId}/{outlineSecret}", func(e *pbCore.RequestEvent) error {
+			return e.Redirect(
+				http.StatusMovedPermanently,
+				m.AccessKeyUrl(e.Request.PathValue("userId"), e.Request.PathValue("outlineSecret")),
 			)
-			return e.Redirect(http.StatusMovedPermanently, url)
 		})
 
 		return se.Next()
 	})
 }
 
+// AccessKeyUrl is the ssconf:// dynamic access key the Outline client
+// loads the connect config from.
+func (m *OutlineModule) AccessKeyUrl(userId string, outlineSecret string) string {
+	return fmt.Sprintf(
+		"ssconf://%s/api/outline/%s/%s#%s",
+		m.appConfig.AppConfig().AppDomain(),
+		userId,
+		outlineSecret,
+		url.PathEscape(m.appConfig.AppConfig().AppTitle()),
+	)
+}
+
+// AvailableServers are the active servers the user can connect to, premium
+// servers are only available to premium users.
+func (m *OutlineModule) AvailableServers(user *models.User) ([]*models.OutlineServer, error) {
+	servers, err := m.GetAllActiveServers()
+	if err != nil {
+		return nil, err
+	}
+
+	availableServers := make([]*models.OutlineServer, 0, len(servers))
+	premium := user.EffectivePremium(time.Now())
+	for _, server := range servers {
+		if !premium && server.Premium() {
+			continue
+		}
+		availableServers = append(availableServers, server)
+	}
+
+	return availableServers, nil
+}
+
 func makeConnectionPrefix(protocol *models.OutlineConfigurationProtocol) []*yaml.Node {
 	decoded, _ := url.QueryUnescape(protocol.Prefix)
 	prefix := ""
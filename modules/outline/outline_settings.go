@@ -3,20 +3,22 @@ package outline
 import (
 	"net/http"
 
+	"github.com/docker-pet/backend/core"
 	"github.com/docker-pet/backend/helpers"
+	"github.com/docker-pet/backend/i18n"
 	"github.com/docker-pet/backend/models"
 	"github.com/docker-pet/backend/modules/users"
 	"github.com/pocketbase/pocketbase/apis"
-	"github.com/pocketbase/pocketbase/core"
+	pbCore "github.com/pocketbase/pocketbase/core"
 )
 
 func (m *OutlineModule) registerSettingsEndpoint() {
-	m.Ctx.App.OnServe().BindFunc(func(se *core.ServeEvent) error {
-		se.Router.POST("/api/outline/settings", func(e *core.RequestEvent) error {
+	m.Ctx.App.OnServe().BindFunc(func(se *pbCore.ServeEvent) error {
+		se.Router.POST("/api/outline/settings", func(e *pbCore.RequestEvent) error {
 			// User
 			user := users.ProxyUser(e.Auth)
 			if user.Role() == models.RoleGuest {
-				return e.UnauthorizedError("Guest users are not allowed to confirm OTP", user)
+				return e.UnauthorizedError(m.Ctx.T(e, "outline.settings.guest_forbidden"), user)
 			}
 
 			// Parse JSON body
@@ -28,19 +30,19 @@ func (m *OutlineModule) registerSettingsEndpoint() {
 			// Prefix enabled
 			outlinePrefixEnabled, ok := data.Path("outlinePrefixEnabled").Data().(bool)
 			if !ok {
-				return e.BadRequestError("field 'outlinePrefixEnabled' must be a bool", nil)
+				return e.BadRequestError(m.Ctx.T(e, "outline.settings.field_bool", i18n.Args{"field": "outlinePrefixEnabled"}), nil)
 			}
 
 			// Picked server
 			outlineServerId, ok := data.Path("outlineServer").Data().(string)
 			if !ok {
-				return e.BadRequestError("field 'outlineServer' must be a string", nil)
+				return e.BadRequestError(m.Ctx.T(e, "outline.settings.field_string", i18n.Args{"field": "outlineServer"}), nil)
 			}
 
 			// Reverse server
 			outlineReverseServerEnabled, ok := data.Path("outlineReverseServerEnabled").Data().(bool)
 			if !ok {
-				return e.BadRequestError("field 'outlineReverseServerEnabled' must be a bool", nil)
+				return e.BadRequestError(m.Ctx.T(e, "outline.settings.field_bool", i18n.Args{"field": "outlineReverseServerEnabled"}), nil)
 			}
 
 			// Check server
@@ -48,21 +50,38 @@ func (m *OutlineModule) registerSettingsEndpoint() {
 			if outlineServerId != "" {
 				_, err := m.GetServerById(outlineServerId)
 				if err != nil {
-					return e.BadRequestError("server with specified ID not found", nil)
+					return e.BadRequestError(m.Ctx.T(e, "outline.settings.server_not_found"), nil)
 				}
 			}
 
 			// Save settings
+			before := outlineSettings(user)
 			user.SetOutlinePrefixEnabled(outlinePrefixEnabled)
 			user.SetOutlineReverseServerEnabled(outlineReverseServerEnabled)
 			user.SetOutlineServer(outlineServerId)
 			if err := m.Ctx.App.Save(user); err != nil {
-				return e.InternalServerError("Failed to save user settings", err)
+				return e.InternalServerError(m.Ctx.T(e, "outline.settings.save_failed"), err)
 			}
 
-			return e.JSON(http.StatusOK, "Settings updated successfully")
+			m.Ctx.Audit(core.AuditEntry{
+				Module:   m.Name(),
+				Action:   core.AuditOutlineSettingsChanged,
+				TargetId: user.Id,
+				Before:   before,
+				After:    outlineSettings(user),
+			}.WithRequest(e))
+
+			return e.JSON(http.StatusOK, m.Ctx.T(e, "outline.settings.saved"))
 		}).Bind(apis.RequireAuth("users"))
 
 		return se.Next()
 	})
 }
+
+func outlineSettings(user *models.User) map[string]any {
+	return map[string]any{
+		"outlinePrefixEnabled":        user.OutlinePrefixEnabled(),
+		"outlineReverseServerEnabled": user.OutlineReverseServerEnabled(),
+		"outlineServer":               user.OutlineServer(),
+	}
+}
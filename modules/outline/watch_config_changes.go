@@ -1,10 +1,13 @@
 package outline
 
 import (
+	"context"
 	"fmt"
+	"strings"
 
+	"github.com/docker-pet/backend/core"
 	"github.com/docker-pet/backend/models"
-	"github.com/pocketbase/pocketbase/core"
+	pbCore "github.com/pocketbase/pocketbase/core"
 )
 
 func (m *OutlineModule) watchConfigChanges() {
@@ -24,7 +27,7 @@ func (m *OutlineModule) watchConfigChanges() {
 		// Remove existing cron jobs
 		cronJobs := m.Ctx.App.Cron().Jobs()
 		for _, job := range cronJobs {
-			if len(job.Id()) >= 8 && job.Id()[:8] == "outline_" {
+			if strings.HasPrefix(job.Id(), cronJobPrefix) {
 				m.Ctx.App.Cron().Remove(job.Id())
 			}
 		}
@@ -41,13 +44,14 @@ func (m *OutlineModule) watchConfigChanges() {
 			}
 
 			m.Ctx.App.Cron().MustAdd(generateCronJobName(server.Slug()), cronExpression, func() {
-				m.configureCaddy(server.Id)
+				ctx := core.WithCorrelationId(context.Background(), core.NewCorrelationId("cron"))
+				m.configureCaddy(ctx, server.Id)
 			})
 		}
 	}
 
 	// On serve
-	m.Ctx.App.OnServe().BindFunc(func(e *core.ServeEvent) error {
+	m.Ctx.App.OnServe().BindFunc(func(e *pbCore.ServeEvent) error {
 		go rebuildConfigs()
 		rebuildCronJobs()
 
@@ -55,22 +59,38 @@ func (m *OutlineModule) watchConfigChanges() {
 		if err != nil {
 			return fmt.Errorf("failed to get servers: %w", err)
 		}
+		ctx := core.WithCorrelationId(context.Background(), core.NewCorrelationId("outline-sync"))
 		for _, server := range servers {
-			go m.configureCaddy(server.Id)
+			go m.configureCaddy(ctx, server.Id)
 		}
 
 		return e.Next()
 	})
 
+	// App domain changed, server domains, Prometheus targets, compose files
+	// and Caddy configs are derived from it
+	core.Subscribe(m.Ctx, func(event core.AppConfigChanged) error {
+		if !event.Changed("appDomain", "appDomainReverse") {
+			return nil
+		}
+
+		ctx := core.WithCorrelationId(context.Background(), core.NewCorrelationId("outline-sync"))
+		go func() {
+			rebuildConfigs()
+			m.configureAll(ctx)
+		}()
+		return nil
+	})
+
 	// Delete
-	m.Ctx.App.OnRecordAfterDeleteSuccess("outline_servers").BindFunc(func(e *core.RecordEvent) error {
+	m.Ctx.App.OnRecordAfterDeleteSuccess("outline_servers").BindFunc(func(e *pbCore.RecordEvent) error {
 		go rebuildConfigs()
 		rebuildCronJobs()
 		return e.Next()
 	})
 
 	// Before create
-	m.Ctx.App.OnRecordCreate("outline_servers").BindFunc(func(e *core.RecordEvent) error {
+	m.Ctx.App.OnRecordCreate("outline_servers").BindFunc(func(e *pbCore.RecordEvent) error {
 		outlineServer := ProxyOutlineServer(e.Record)
 		outlineServer.GenerateMetricsSecret()
 		if outlineServer.SyncType() == models.OutlineLocalSync {
@@ -82,18 +102,17 @@ func (m *OutlineModule) watchConfigChanges() {
 	})
 
 	// Create
-	m.Ctx.App.OnRecordAfterCreateSuccess("outline_servers").BindFunc(func(e *core.RecordEvent) error {
+	m.Ctx.App.OnRecordAfterCreateSuccess("outline_servers").BindFunc(func(e *pbCore.RecordEvent) error {
 		outlineServer := ProxyOutlineServer(e.Record)
 		go rebuildConfigs()
 		rebuildCronJobs()
-		m.configureCaddy(outlineServer.Id)
+		m.configureCaddy(e.Context, outlineServer.Id)
 		return e.Next()
 	})
 
 	// Before update
-	m.Ctx.App.OnRecordUpdate("outline_servers").BindFunc(func(e *core.RecordEvent) error {
+	m.Ctx.App.OnRecordUpdate("outline_servers").BindFunc(func(e *pbCore.RecordEvent) error {
 		outlineServer := ProxyOutlineServer(e.Record)
-		outlineServer.GenerateMetricsSecret()
 		if outlineServer.SyncType() == models.OutlineLocalSync {
 			outlineServer.SetSyncLocalConfig(outlineServer.SyncLocalConfig())
 		} else {
@@ -108,15 +127,17 @@ func (m *OutlineModule) watchConfigChanges() {
 	})
 
 	// Update
-	m.Ctx.App.OnRecordAfterUpdateSuccess("outline_servers").BindFunc(func(e *core.RecordEvent) error {
+	m.Ctx.App.OnRecordAfterUpdateSuccess("outline_servers").BindFunc(func(e *pbCore.RecordEvent) error {
 		outlineServer := ProxyOutlineServer(e.Record)
 		go rebuildConfigs()
-		go m.configureCaddy(outlineServer.Id)
+		go m.configureCaddy(context.WithoutCancel(e.Context), outlineServer.Id)
 		rebuildCronJobs()
 		return e.Next()
 	})
 }
 
+const cronJobPrefix = "outline_"
+
 func generateCronJobName(serverSlug string) string {
-	return "outline_" + serverSlug
+	return cronJobPrefix + serverSlug
 }
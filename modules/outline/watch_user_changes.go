@@ -1,53 +1,46 @@
 package outline
 
 import (
+	"context"
 	"time"
 
-	"github.com/docker-pet/backend/models"
-	"github.com/docker-pet/backend/modules/users"
-	"github.com/pocketbase/pocketbase/core"
+	"github.com/docker-pet/backend/core"
+	pbCore "github.com/pocketbase/pocketbase/core"
 	"github.com/zmwangx/debounce"
 )
 
 func (m *OutlineModule) watchUsersChanges() {
-	configureAll, _ := debounce.Debounce(
-		func() { m.configureAll() },
+	configureAll, configureAllControl := debounce.Debounce(
+		func() {
+			m.configureAll(core.WithCorrelationId(context.Background(), core.NewCorrelationId("outline-sync")))
+		},
 		2*time.Second, // TODO: make configurable
 		debounce.WithLeading(true),
 		debounce.WithTrailing(true),
 	)
+	m.configureAllControl = configureAllControl
 
 	// On app start
-	m.Ctx.App.OnServe().BindFunc(func(e *core.ServeEvent) error {
+	m.Ctx.App.OnServe().BindFunc(func(e *pbCore.ServeEvent) error {
 		configureAll()
 		return e.Next()
 	})
 
-	// User created
-	m.Ctx.App.OnRecordAfterCreateSuccess("users").BindFunc(func(e *core.RecordEvent) error {
-		user := users.ProxyUser(e.Record)
-		if user.Role() == models.RoleGuest {
-			return e.Next()
-		}
-
+	// Only changes of the issued keys require a Caddy reconfiguration
+	core.Subscribe(m.Ctx, func(core.UserActivated) error {
 		configureAll()
-		return e.Next()
+		return nil
 	})
-
-	// User updated
-	m.Ctx.App.OnRecordAfterUpdateSuccess("users").BindFunc(func(e *core.RecordEvent) error {
+	core.Subscribe(m.Ctx, func(core.UserDeactivated) error {
 		configureAll()
-		return e.Next()
+		return nil
 	})
-
-	// User deleted
-	m.Ctx.App.OnRecordDelete("users").BindFunc(func(e *core.RecordEvent) error {
-		user := users.ProxyUser(e.Record)
-		if user.Role() == models.RoleGuest {
-			return e.Next()
-		}
-
+	core.Subscribe(m.Ctx, func(core.OutlineTokenRotated) error {
 		configureAll()
-		return e.Next()
+		return nil
+	})
+	core.Subscribe(m.Ctx, func(core.PremiumChanged) error {
+		configureAll()
+		return nil
 	})
 }
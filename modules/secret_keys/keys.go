@@ -0,0 +1,145 @@
+package secret_keys
+
+import (
+	"context"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+
+	"github.com/docker-pet/backend/core"
+	"github.com/docker-pet/backend/models"
+	"github.com/pocketbase/dbx"
+	pbCore "github.com/pocketbase/pocketbase/core"
+	"github.com/pocketbase/pocketbase/tools/types"
+)
+
+const cronCleanupJobId = "secret_keys_cleanup"
+
+// KeyId derives the key id of a secret, so the current secret needs no
+// stored id and tokens can name the key they were signed with.
+func KeyId(secret string) string {
+	sum := sha256.Sum256([]byte(secret))
+	return hex.EncodeToString(sum[:8])
+}
+
+// Resolve returns the secret with the given key id: the current one or a
+// retired one still within its grace period.
+func (m *SecretKeysModule) Resolve(scope string, recordId string, current string, kid string) (string, bool) {
+	if kid == KeyId(current) {
+		return current, true
+	}
+
+	for _, key := range m.retiredKeys(scope, recordId) {
+		if key.Kid() == kid {
+			return key.Secret(), true
+		}
+	}
+	return "", false
+}
+
+// Accepts reports whether the secret is the current one or a retired one
+// still within its grace period.
+func (m *SecretKeysModule) Accepts(scope string, recordId string, current string, secret string) bool {
+	if subtle.ConstantTimeCompare([]byte(secret), []byte(current)) == 1 {
+		return true
+	}
+
+	for _, key := range m.retiredKeys(scope, recordId) {
+		if subtle.ConstantTimeCompare([]byte(secret), []byte(key.Secret())) == 1 {
+			return true
+		}
+	}
+	return false
+}
+
+// retiredKeys lists the not yet expired retired keys, newest first.
+func (m *SecretKeysModule) retiredKeys(scope string, recordId string) []*models.SecretKey {
+	records, err := m.Ctx.App.FindRecordsByFilter(
+		"secret_keys",
+		"scope = {:scope} && recordId = {:recordId} && expires > {:now}",
+		"-@rowid",
+		0,
+		0,
+		dbx.Params{"scope": scope, "recordId": recordId, "now": types.NowDateTime().String()},
+	)
+	if err != nil {
+		m.Logger.Warn("Failed to find retired secret keys", "Scope", scope, "Err", err)
+		return nil
+	}
+
+	keys := make([]*models.SecretKey, len(records))
+	for i, record := range records {
+		keys[i] = proxySecretKey(record)
+	}
+	return keys
+}
+
+// retireReplacedSecrets keeps a replaced secret for the grace period of
+// its scope, whether it was rotated through the API, edited in the admin UI
+// or restored by a config rollback.
+func (m *SecretKeysModule) retireReplacedSecrets() {
+	for name, scope := range secretScopes {
+		m.Ctx.App.OnRecordAfterUpdateSuccess(scope.collection).BindFunc(func(e *pbCore.RecordEvent) error {
+			previous := e.Record.Original().GetString(scope.field)
+			gracePeriod := scope.gracePeriod(m.Config)
+			if previous == "" || previous == e.Record.GetString(scope.field) || gracePeriod <= 0 {
+				return e.Next()
+			}
+
+			collection, err := m.Ctx.App.FindCachedCollectionByNameOrId("secret_keys")
+			if err != nil {
+				return err
+			}
+
+			key := proxySecretKey(pbCore.NewRecord(collection))
+			key.SetScope(name)
+			key.SetRecordId(e.Record.Id)
+			key.SetKid(KeyId(previous))
+			key.SetSecret(previous)
+			key.SetExpires(types.NowDateTime().Add(gracePeriod))
+
+			if err := m.Ctx.App.SaveWithContext(e.Context, key); err != nil {
+				core.Logger(e.Context, m.Logger).Error("Failed to retire secret key", "Scope", name, "Err", err)
+			}
+			return e.Next()
+		})
+	}
+}
+
+func (m *SecretKeysModule) useCleanupCron() {
+	m.Ctx.App.Cron().MustAdd(cronCleanupJobId, m.Config.CronCleanupExpression, func() {
+		ctx := core.WithCorrelationId(context.Background(), core.NewCorrelationId("cron"))
+
+		_, err := m.Ctx.App.DB().
+			Delete("secret_keys", dbx.NewExp("expires <= {:now}", dbx.Params{"now": types.NowDateTime().String()})).
+			WithContext(ctx).
+			Execute()
+		if err != nil {
+			core.Logger(ctx, m.Logger).Warn("Failed to remove expired secret keys", "Err", err)
+		}
+	})
+}
+
+func proxySecretKey(record *pbCore.Record) *models.SecretKey {
+	key := &models.SecretKey{}
+	key.SetProxyRecord(record)
+	return key
+}
+
+// keyInfo describes a key without its secret.
+type keyInfo struct {
+	Kid     string          `json:"kid"`
+	Current bool            `json:"current"`
+	Retired *types.DateTime `json:"retired,omitempty"`
+	Expires *types.DateTime `json:"expires,omitempty"`
+}
+
+func (m *SecretKeysModule) activeKeys(scope string, record *pbCore.Record) []keyInfo {
+	field := secretScopes[scope].field
+	keys := []keyInfo{{Kid: KeyId(record.GetString(field)), Current: true}}
+	for _, key := range m.retiredKeys(scope, record.Id) {
+		retired, expires := key.Created(), key.Expires()
+		keys = append(keys, keyInfo{Kid: key.Kid(), Retired: &retired, Expires: &expires})
+	}
+	return keys
+}
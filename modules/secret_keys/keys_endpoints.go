@@ -0,0 +1,86 @@
+package secret_keys
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/docker-pet/backend/core"
+	pbCore "github.com/pocketbase/pocketbase/core"
+)
+
+func (m *SecretKeysModule) registerKeysEndpoints() {
+	m.Ctx.App.OnServe().BindFunc(func(se *pbCore.ServeEvent) error {
+		group := se.Router.Group("/api/secret_keys")
+		group.Bind(core.RequireAdmin())
+
+		// List the current and the still accepted retired key ids. The
+		// record id is required for outline_metrics_secret only.
+		group.GET("/{scope}/keys", func(e *pbCore.RequestEvent) error {
+			scope := e.Request.PathValue("scope")
+			record, err := m.findScopeRecord(scope, e.Request.URL.Query().Get("recordId"))
+			if err != nil {
+				return e.NotFoundError(m.Ctx.T(e, "secret_keys.secret_not_found"), err)
+			}
+
+			return e.JSON(http.StatusOK, map[string]any{
+				"scope":       scope,
+				"recordId":    record.Id,
+				"gracePeriod": secretScopes[scope].gracePeriod(m.Config).String(),
+				"keys":        m.activeKeys(scope, record),
+			})
+		})
+
+		// Generate a new secret. The record is saved through the regular
+		// hooks, so the previous secret is retired and artifacts are rebuilt.
+		group.POST("/{scope}/rotate", func(e *pbCore.RequestEvent) error {
+			var body struct {
+				RecordId string `json:"recordId"`
+			}
+			if err := e.BindBody(&body); err != nil {
+				return e.BadRequestError(m.Ctx.T(e, "secret_keys.body_invalid"), err)
+			}
+
+			scope := e.Request.PathValue("scope")
+			record, err := m.findScopeRecord(scope, body.RecordId)
+			if err != nil {
+				return e.NotFoundError(m.Ctx.T(e, "secret_keys.secret_not_found"), err)
+			}
+
+			previousKid := KeyId(record.GetString(secretScopes[scope].field))
+			secretScopes[scope].generate(record)
+			if err := m.Ctx.App.SaveWithContext(e.Request.Context(), record); err != nil {
+				return e.BadRequestError(m.Ctx.T(e, "secret_keys.rotate_failed"), err)
+			}
+
+			m.Ctx.Audit(core.AuditEntry{
+				Module: m.Name(),
+				Action: core.AuditSecretRotated,
+				Before: map[string]any{"scope": scope, "recordId": record.Id, "kid": previousKid},
+				After:  map[string]any{"scope": scope, "recordId": record.Id, "kid": KeyId(record.GetString(secretScopes[scope].field))},
+			}.WithRequest(e))
+
+			return e.JSON(http.StatusOK, map[string]any{
+				"scope":    scope,
+				"recordId": record.Id,
+				"keys":     m.activeKeys(scope, record),
+			})
+		})
+
+		return se.Next()
+	})
+}
+
+func (m *SecretKeysModule) findScopeRecord(scope string, recordId string) (*pbCore.Record, error) {
+	secretScope, ok := secretScopes[scope]
+	if !ok {
+		return nil, errors.New("unknown secret scope " + scope)
+	}
+
+	if recordId == "" {
+		if !secretScope.singleton {
+			return nil, errors.New("recordId is required for scope " + scope)
+		}
+		return m.Ctx.App.FindFirstRecordByFilter(secretScope.collection, "")
+	}
+	return m.Ctx.App.FindRecordById(secretScope.collection, recordId)
+}
@@ -0,0 +1,60 @@
+package secret_keys
+
+import (
+	"time"
+
+	"github.com/docker-pet/backend/models"
+	pbCore "github.com/pocketbase/pocketbase/core"
+)
+
+// Secret scopes
+const (
+	ScopeAuthSecret           = "auth_secret"
+	ScopeLampaAdminPassword   = "lampa_admin_password"
+	ScopeOutlineMetricsSecret = "outline_metrics_secret"
+)
+
+type secretScope struct {
+	collection  string
+	field       string
+	singleton   bool // The collection has a single config record
+	gracePeriod func(config *Config) time.Duration
+	generate    func(record *pbCore.Record)
+}
+
+var secretScopes = map[string]secretScope{
+	ScopeAuthSecret: {
+		collection:  "app",
+		field:       "authSecret",
+		singleton:   true,
+		gracePeriod: func(config *Config) time.Duration { return config.AuthSecretGracePeriod },
+		generate: func(record *pbCore.Record) {
+			appConfig := &models.AppConfig{}
+			appConfig.SetProxyRecord(record)
+			appConfig.GenerateAuthSecret("")
+		},
+	},
+	// Lampac reads the password from the passwd file and knows a single
+	// one, so the previous password stops working on rotation
+	ScopeLampaAdminPassword: {
+		collection:  "lampa",
+		field:       "adminPassword",
+		singleton:   true,
+		gracePeriod: func(config *Config) time.Duration { return 0 },
+		generate: func(record *pbCore.Record) {
+			lampaConfig := &models.LampaConfig{}
+			lampaConfig.SetProxyRecord(record)
+			lampaConfig.GenerateAdminPassword()
+		},
+	},
+	ScopeOutlineMetricsSecret: {
+		collection:  "outline_servers",
+		field:       "metricsSecret",
+		gracePeriod: func(config *Config) time.Duration { return config.MetricsSecretGracePeriod },
+		generate: func(record *pbCore.Record) {
+			server := &models.OutlineServer{}
+			server.SetProxyRecord(record)
+			server.GenerateMetricsSecret()
+		},
+	},
+}
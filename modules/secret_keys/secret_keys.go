@@ -0,0 +1,51 @@
+package secret_keys
+
+import (
+	"errors"
+	"log/slog"
+	"time"
+
+	"github.com/docker-pet/backend/core"
+)
+
+type Config struct {
+	AuthSecretGracePeriod    time.Duration `yaml:"authSecretGracePeriod"`    // How long cookies signed with a previous authSecret stay valid
+	MetricsSecretGracePeriod time.Duration `yaml:"metricsSecretGracePeriod"` // How long a previous outline metricsSecret is accepted by the metrics proxy
+	CronCleanupExpression    string        `yaml:"cronCleanupExpression"`    // Cron expression for removing expired keys
+}
+
+func (c *Config) Validate() error {
+	var errs []error
+	if c.AuthSecretGracePeriod < 0 {
+		errs = append(errs, errors.New("authSecretGracePeriod must not be negative"))
+	}
+	if c.MetricsSecretGracePeriod < 0 {
+		errs = append(errs, errors.New("metricsSecretGracePeriod must not be negative"))
+	}
+	if c.CronCleanupExpression == "" {
+		errs = append(errs, errors.New("cronCleanupExpression is required"))
+	}
+	return errors.Join(errs...)
+}
+
+type SecretKeysModule struct {
+	Ctx    *core.AppContext
+	Config *Config
+	Logger *slog.Logger
+}
+
+func (m *SecretKeysModule) Name() string                  { return "secret_keys" }
+func (m *SecretKeysModule) Deps() []string                { return nil }
+func (m *SecretKeysModule) SetLogger(logger *slog.Logger) { m.Logger = logger }
+func (m *SecretKeysModule) Init(ctx *core.AppContext, logger *slog.Logger, cfg any) error {
+	m.Ctx = ctx
+	m.Config = cfg.(*Config)
+	m.Logger = logger
+
+	m.retireReplacedSecrets()
+	m.useCleanupCron()
+	m.registerKeysEndpoints()
+
+	m.Logger.Info("Secret keys module initialized", "Config", m.Config)
+	return nil
+}
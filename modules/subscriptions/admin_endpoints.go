@@ -0,0 +1,152 @@
+package subscriptions
+
+import (
+	"net/http"
+	"slices"
+
+	"github.com/docker-pet/backend/core"
+	"github.com/docker-pet/backend/models"
+	pbCore "github.com/pocketbase/pocketbase/core"
+	"github.com/pocketbase/pocketbase/tools/types"
+)
+
+type periodResponse struct {
+	Id       string                  `json:"id"`
+	Kind     models.SubscriptionKind `json:"kind"`
+	Starts   types.DateTime          `json:"starts"`
+	Ends     types.DateTime          `json:"ends"`
+	Note     string                  `json:"note"`
+	Notified bool                    `json:"notified"`
+	Expired  bool                    `json:"expired"`
+}
+
+type subscriptionsResponse struct {
+	UserId            string           `json:"userId"`
+	AccessPeriodEnds  types.DateTime   `json:"accessPeriodEnds"`
+	PremiumPeriodEnds types.DateTime   `json:"premiumPeriodEnds"`
+	Periods           []periodResponse `json:"periods"`
+}
+
+func (m *SubscriptionsModule) registerAdminEndpoints() {
+	m.Ctx.App.OnServe().BindFunc(func(se *pbCore.ServeEvent) error {
+		group := se.Router.Group("/api/admin")
+		group.Bind(core.RequireAdmin())
+
+		group.GET("/users/{id}/subscriptions", func(e *pbCore.RequestEvent) error {
+			user, err := m.users.GetUserById(e.Request.PathValue("id"))
+			if err != nil {
+				return e.NotFoundError(m.Ctx.T(e, "subscriptions.user_not_found"), err)
+			}
+
+			return m.sendSubscriptions(e, user)
+		})
+
+		// Starts defaults to now
+		group.POST("/users/{id}/subscriptions", func(e *pbCore.RequestEvent) error {
+			user, err := m.users.GetUserById(e.Request.PathValue("id"))
+			if err != nil {
+				return e.NotFoundError(m.Ctx.T(e, "subscriptions.user_not_found"), err)
+			}
+
+			var body struct {
+				Kind   models.SubscriptionKind `json:"kind"`
+				Starts types.DateTime          `json:"starts"`
+				Ends   types.DateTime          `json:"ends"`
+				Note   string                  `json:"note"`
+			}
+			if err := e.BindBody(&body); err != nil {
+				return e.BadRequestError(m.Ctx.T(e, "subscriptions.body_invalid"), err)
+			}
+			if body.Kind != models.SubscriptionAccess && body.Kind != models.SubscriptionPremium {
+				return e.BadRequestError(m.Ctx.T(e, "subscriptions.kind_invalid"), nil)
+			}
+			if body.Starts.IsZero() {
+				body.Starts = types.NowDateTime()
+			}
+			if !body.Ends.Time().After(body.Starts.Time()) {
+				return e.BadRequestError(m.Ctx.T(e, "subscriptions.ends_before_starts"), nil)
+			}
+
+			collection, err := m.Ctx.App.FindCachedCollectionByNameOrId(periodsCollection)
+			if err != nil {
+				return e.InternalServerError(m.Ctx.T(e, "subscriptions.save_failed"), err)
+			}
+
+			period := ProxySubscriptionPeriod(pbCore.NewRecord(collection))
+			period.SetUserId(user.Id)
+			period.SetKind(body.Kind)
+			period.SetStarts(body.Starts)
+			period.SetEnds(body.Ends)
+			period.SetNote(body.Note)
+			if err := m.Ctx.App.SaveWithContext(e.Request.Context(), period); err != nil {
+				return e.BadRequestError(m.Ctx.T(e, "subscriptions.save_failed"), err)
+			}
+
+			m.Ctx.Audit(core.AuditEntry{
+				Module:   m.Name(),
+				Action:   core.AuditSubscriptionGranted,
+				TargetId: user.Id,
+				After:    map[string]any{"periodId": period.Id, "kind": body.Kind, "starts": body.Starts, "ends": body.Ends},
+			}.WithRequest(e))
+
+			return m.sendSubscriptions(e, user)
+		})
+
+		group.DELETE("/subscriptions/{id}", func(e *pbCore.RequestEvent) error {
+			period, err := m.GetPeriodById(e.Request.PathValue("id"))
+			if err != nil {
+				return e.NotFoundError(m.Ctx.T(e, "subscriptions.period_not_found"), err)
+			}
+
+			if err := m.Ctx.App.DeleteWithContext(e.Request.Context(), period); err != nil {
+				return e.BadRequestError(m.Ctx.T(e, "subscriptions.delete_failed"), err)
+			}
+
+			m.Ctx.Audit(core.AuditEntry{
+				Module:   m.Name(),
+				Action:   core.AuditSubscriptionRevoked,
+				TargetId: period.UserId(),
+				Before:   map[string]any{"periodId": period.Id, "kind": period.Kind(), "starts": period.Starts(), "ends": period.Ends()},
+			}.WithRequest(e))
+
+			return e.NoContent(http.StatusNoContent)
+		})
+
+		return se.Next()
+	})
+}
+
+func (m *SubscriptionsModule) sendSubscriptions(e *pbCore.RequestEvent, user *models.User) error {
+	periods, err := m.GetUserPeriods(user.Id)
+	if err != nil {
+		return e.InternalServerError(m.Ctx.T(e, "subscriptions.list_failed"), err)
+	}
+	slices.SortFunc(periods, func(a, b *models.SubscriptionPeriod) int {
+		return a.Starts().Time().Compare(b.Starts().Time())
+	})
+
+	// Saving a period has synced the user record
+	if fresh, err := m.users.GetUserById(user.Id); err == nil {
+		user = fresh
+	}
+
+	response := subscriptionsResponse{
+		UserId:            user.Id,
+		AccessPeriodEnds:  user.AccessPeriodEnds(),
+		PremiumPeriodEnds: user.PremiumPeriodEnds(),
+		Periods:           make([]periodResponse, len(periods)),
+	}
+	for i, period := range periods {
+		response.Periods[i] = periodResponse{
+			Id:       period.Id,
+			Kind:     period.Kind(),
+			Starts:   period.Starts(),
+			Ends:     period.Ends(),
+			Note:     period.Note(),
+			Notified: period.Notified(),
+			Expired:  period.Expired(),
+		}
+	}
+
+	return e.JSON(http.StatusOK, response)
+}
@@ -0,0 +1,146 @@
+package subscriptions
+
+import (
+	"context"
+	"time"
+
+	"github.com/docker-pet/backend/core"
+	"github.com/docker-pet/backend/models"
+	"github.com/pocketbase/dbx"
+	"github.com/pocketbase/pocketbase/tools/types"
+)
+
+const cronExpiryJobId = "subscriptions_expiry"
+
+// useExpiryCron applies periods that have started, expires the ended ones
+// and warns users about the upcoming end of their last period.
+func (m *SubscriptionsModule) useExpiryCron() {
+	m.Ctx.App.Cron().MustAdd(cronExpiryJobId, m.Config.CronExpiryExpression, func() {
+		ctx := core.WithCorrelationId(context.Background(), core.NewCorrelationId("cron"))
+		now := time.Now()
+
+		m.startPeriods(ctx, now)
+		m.expirePeriods(ctx, now)
+		m.notifyExpiringPeriods(ctx, now)
+	})
+}
+
+// startPeriods syncs the owners of the active periods, which picks up
+// periods that started since the last run.
+func (m *SubscriptionsModule) startPeriods(ctx context.Context, now time.Time) {
+	logger := core.Logger(ctx, m.Logger)
+
+	periods, err := m.findPeriods(dbx.NewExp(
+		"starts <= {:now} AND ends > {:now}",
+		dbx.Params{"now": dateString(now)},
+	))
+	if err != nil {
+		logger.Warn("Failed to find active subscription periods", "Err", err)
+		return
+	}
+
+	synced := map[string]bool{}
+	for _, period := range periods {
+		if synced[period.UserId()] {
+			continue
+		}
+		synced[period.UserId()] = true
+
+		if err := m.syncUser(ctx, period.UserId()); err != nil {
+			logger.Error("Failed to sync subscription periods", "UserId", period.UserId(), "Error", err)
+		}
+	}
+}
+
+func (m *SubscriptionsModule) expirePeriods(ctx context.Context, now time.Time) {
+	logger := core.Logger(ctx, m.Logger)
+
+	periods, err := m.findPeriods(dbx.NewExp(
+		"expired = FALSE AND ends <= {:now}",
+		dbx.Params{"now": dateString(now)},
+	))
+	if err != nil {
+		logger.Warn("Failed to find ended subscription periods", "Err", err)
+		return
+	}
+
+	for _, period := range periods {
+		// Saving the period syncs its owner
+		period.SetExpired(true)
+		if err := m.Ctx.App.SaveWithContext(ctx, period); err != nil {
+			logger.Error("Failed to expire subscription period", "PeriodId", period.Id, "Error", err)
+			continue
+		}
+
+		m.Ctx.Audit(core.AuditEntry{
+			Module:     m.Name(),
+			Action:     core.AuditSubscriptionExpired,
+			ActorLabel: core.AuditActorSystem,
+			TargetId:   period.UserId(),
+			Before:     map[string]any{"periodId": period.Id, "kind": period.Kind(), "ends": period.Ends()},
+		})
+
+		// The user may have a following period
+		user, err := m.users.GetUserById(period.UserId())
+		if err != nil {
+			continue
+		}
+		if !hasPeriod(user, period.Kind(), now) {
+			core.Publish(m.Ctx, core.SubscriptionExpired{User: user, Kind: period.Kind()})
+		}
+		logger.Info("Subscription period expired", "UserId", user.Id, "PeriodId", period.Id, "Kind", period.Kind())
+	}
+}
+
+func (m *SubscriptionsModule) notifyExpiringPeriods(ctx context.Context, now time.Time) {
+	logger := core.Logger(ctx, m.Logger)
+
+	periods, err := m.findPeriods(dbx.NewExp(
+		"notified = FALSE AND expired = FALSE AND starts <= {:now} AND ends > {:now} AND ends <= {:notifyAt}",
+		dbx.Params{"now": dateString(now), "notifyAt": dateString(now.Add(m.Config.NotifyBefore))},
+	))
+	if err != nil {
+		logger.Warn("Failed to find expiring subscription periods", "Err", err)
+		return
+	}
+
+	for _, period := range periods {
+		user, err := m.users.GetUserById(period.UserId())
+		if err != nil {
+			continue
+		}
+
+		// Only the last period of a chain is about to end
+		if periodEnds(user, period.Kind()).Equal(period.Ends()) {
+			core.Publish(m.Ctx, core.SubscriptionExpiring{
+				User: user,
+				Kind: period.Kind(),
+				Ends: period.Ends().Time(),
+			})
+		}
+
+		period.SetNotified(true)
+		if err := m.Ctx.App.SaveWithContext(ctx, period); err != nil {
+			logger.Error("Failed to mark subscription period as notified", "PeriodId", period.Id, "Error", err)
+		}
+	}
+}
+
+func periodEnds(user *models.User, kind models.SubscriptionKind) types.DateTime {
+	if kind == models.SubscriptionPremium {
+		return user.PremiumPeriodEnds()
+	}
+	return user.AccessPeriodEnds()
+}
+
+func hasPeriod(user *models.User, kind models.SubscriptionKind, now time.Time) bool {
+	if kind == models.SubscriptionPremium {
+		return user.HasPremiumPeriod(now)
+	}
+	return user.HasAccessPeriod(now)
+}
+
+func dateString(t time.Time) string {
+	date, _ := types.ParseDateTime(t)
+	return date.String()
+}
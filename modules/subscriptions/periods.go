@@ -0,0 +1,139 @@
+package subscriptions
+
+import (
+	"context"
+	"slices"
+	"time"
+
+	"github.com/docker-pet/backend/models"
+	validation "github.com/go-ozzo/ozzo-validation/v4"
+	"github.com/pocketbase/dbx"
+	pbCore "github.com/pocketbase/pocketbase/core"
+	"github.com/pocketbase/pocketbase/tools/types"
+)
+
+const periodsCollection = "subscription_periods"
+
+func (m *SubscriptionsModule) GetPeriodById(id string) (*models.SubscriptionPeriod, error) {
+	record, err := m.Ctx.App.FindRecordById(periodsCollection, id)
+	if err != nil {
+		return nil, err
+	}
+
+	return ProxySubscriptionPeriod(record), nil
+}
+
+func (m *SubscriptionsModule) GetUserPeriods(userId string) ([]*models.SubscriptionPeriod, error) {
+	return m.findPeriods(dbx.HashExp{"user": userId})
+}
+
+func (m *SubscriptionsModule) findPeriods(exprs ...dbx.Expression) ([]*models.SubscriptionPeriod, error) {
+	records, err := m.Ctx.App.FindAllRecords(periodsCollection, exprs...)
+	if err != nil {
+		return nil, err
+	}
+
+	periods := make([]*models.SubscriptionPeriod, len(records))
+	for i, record := range records {
+		periods[i] = ProxySubscriptionPeriod(record)
+	}
+
+	return periods, nil
+}
+
+// watchPeriodChanges validates periods and keeps the period ends of the
+// owner in sync with every change, whoever made it.
+func (m *SubscriptionsModule) watchPeriodChanges() {
+	validate := func(e *pbCore.RecordEvent) error {
+		period := ProxySubscriptionPeriod(e.Record)
+		if !period.Ends().Time().After(period.Starts().Time()) {
+			return validation.Errors{
+				"ends": validation.NewError(
+					"validation_ends_before_starts",
+					m.Ctx.I18n.T(m.Ctx.I18n.FallbackLocale(), "subscriptions.ends_before_starts"),
+				),
+			}
+		}
+		return e.Next()
+	}
+	m.Ctx.App.OnRecordCreate(periodsCollection).BindFunc(validate)
+	m.Ctx.App.OnRecordUpdate(periodsCollection).BindFunc(validate)
+
+	sync := func(e *pbCore.RecordEvent) error {
+		if err := e.Next(); err != nil {
+			return err
+		}
+
+		period := ProxySubscriptionPeriod(e.Record)
+		if err := m.syncUser(e.Context, period.UserId()); err != nil {
+			m.Logger.Error("Failed to sync subscription periods", "UserId", period.UserId(), "Error", err)
+		}
+		return nil
+	}
+	m.Ctx.App.OnRecordAfterCreateSuccess(periodsCollection).BindFunc(sync)
+	m.Ctx.App.OnRecordAfterUpdateSuccess(periodsCollection).BindFunc(sync)
+	m.Ctx.App.OnRecordAfterDeleteSuccess(periodsCollection).BindFunc(sync)
+}
+
+// syncUser stores the end of the current access and premium periods on the
+// user. Saving the user recomputes the effective role and premium.
+func (m *SubscriptionsModule) syncUser(ctx context.Context, userId string) error {
+	user, err := m.users.GetUserById(userId)
+	if err != nil {
+		// Periods of a deleted user are removed with it
+		return nil
+	}
+
+	periods, err := m.GetUserPeriods(userId)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	accessEnds := PeriodEnds(periods, models.SubscriptionAccess, now)
+	premiumEnds := PeriodEnds(periods, models.SubscriptionPremium, now)
+	if user.AccessPeriodEnds().Equal(accessEnds) && user.PremiumPeriodEnds().Equal(premiumEnds) {
+		return nil
+	}
+
+	user.SetAccessPeriodEnds(accessEnds)
+	user.SetPremiumPeriodEnds(premiumEnds)
+	return m.Ctx.App.SaveWithContext(ctx, user)
+}
+
+// PeriodEnds is the end of the periods of the kind active at now, followed
+// by the periods starting before it ends. Empty without an active period.
+func PeriodEnds(periods []*models.SubscriptionPeriod, kind models.SubscriptionKind, now time.Time) types.DateTime {
+	ofKind := make([]*models.SubscriptionPeriod, 0, len(periods))
+	for _, period := range periods {
+		if period.Kind() == kind {
+			ofKind = append(ofKind, period)
+		}
+	}
+	slices.SortFunc(ofKind, func(a, b *models.SubscriptionPeriod) int {
+		return a.Starts().Time().Compare(b.Starts().Time())
+	})
+
+	var ends time.Time
+	for _, period := range ofKind {
+		starts := period.Starts().Time()
+		if starts.After(now) && (ends.IsZero() || starts.After(ends)) {
+			break
+		}
+		if period.Ends().Time().After(ends) && period.Ends().Time().After(now) {
+			ends = period.Ends().Time()
+		}
+	}
+
+	if ends.IsZero() {
+		return types.DateTime{}
+	}
+	date, _ := types.ParseDateTime(ends)
+	return date
+}
+
+func ProxySubscriptionPeriod(record *pbCore.Record) *models.SubscriptionPeriod {
+	period := &models.SubscriptionPeriod{}
+	period.SetProxyRecord(record)
+	return period
+}
@@ -0,0 +1,61 @@
+package subscriptions
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/docker-pet/backend/core"
+	"github.com/docker-pet/backend/modules/users"
+	"github.com/pocketbase/pocketbase/tools/cron"
+)
+
+type Config struct {
+	CronExpiryExpression string        `yaml:"cronExpiryExpression"` // Cron expression for starting, expiring and notifying about periods
+	NotifyBefore         time.Duration `yaml:"notifyBefore"`         // How long before the expiry users are notified
+}
+
+func (c *Config) Validate() error {
+	var errs []error
+	if _, err := cron.NewSchedule(c.CronExpiryExpression); err != nil {
+		errs = append(errs, fmt.Errorf("cronExpiryExpression: %w", err))
+	}
+	if c.NotifyBefore < 0 {
+		errs = append(errs, errors.New("notifyBefore must not be negative"))
+	}
+	return errors.Join(errs...)
+}
+
+type SubscriptionsModule struct {
+	Ctx    *core.AppContext
+	Config *Config
+	Logger *slog.Logger
+
+	users *users.UsersModule
+}
+
+func (m *SubscriptionsModule) Name() string                  { return "subscriptions" }
+func (m *SubscriptionsModule) Deps() []string                { return []string{"users"} }
+func (m *SubscriptionsModule) SetLogger(logger *slog.Logger) { m.Logger = logger }
+func (m *SubscriptionsModule) Init(ctx *core.AppContext, logger *slog.Logger, cfg any) error {
+	m.Ctx = ctx
+	m.Config = cfg.(*Config)
+	m.Logger = logger
+	if err := core.Require(ctx, &m.users); err != nil {
+		return err
+	}
+
+	m.watchPeriodChanges()
+	m.useExpiryCron()
+	m.registerAdminEndpoints()
+
+	m.Logger.Info("Subscriptions module initialized", "Config", m.Config)
+	return nil
+}
+
+func (m *SubscriptionsModule) Stop(ctx context.Context) error {
+	m.Ctx.App.Cron().Remove(cronExpiryJobId)
+	return nil
+}
@@ -0,0 +1,188 @@
+package telegram_bot
+
+import (
+	"context"
+	"slices"
+
+	"github.com/docker-pet/backend/core"
+	"github.com/docker-pet/backend/models"
+	"github.com/pocketbase/dbx"
+	pbCore "github.com/pocketbase/pocketbase/core"
+	tele "gopkg.in/telebot.v4"
+)
+
+const accessChannelsCollection = "access_channels"
+
+// loadAccessChannels caches the enabled access channels, highest priority
+// first.
+func (m *TelegramBotModule) loadAccessChannels() error {
+	records, err := m.Ctx.App.FindAllRecords(accessChannelsCollection, dbx.HashExp{"enabled": true})
+	if err != nil {
+		return err
+	}
+
+	channels := make([]*models.AccessChannel, len(records))
+	for i, record := range records {
+		channels[i] = &models.AccessChannel{}
+		channels[i].SetProxyRecord(record)
+	}
+	slices.SortStableFunc(channels, func(a, b *models.AccessChannel) int {
+		return b.Priority() - a.Priority()
+	})
+
+	m.channelsMu.Lock()
+	m.channels = channels
+	m.channelsMu.Unlock()
+
+	m.Logger.Debug("Access channels loaded", "Count", len(channels))
+	return nil
+}
+
+func (m *TelegramBotModule) accessChannels() []*models.AccessChannel {
+	m.channelsMu.RLock()
+	defer m.channelsMu.RUnlock()
+	return m.channels
+}
+
+// accessChannel is the enabled access channel of the chat, nil for other
+// chats.
+func (m *TelegramBotModule) accessChannel(chatId int64) *models.AccessChannel {
+	for _, channel := range m.accessChannels() {
+		if channel.ChatId() == chatId {
+			return channel
+		}
+	}
+	return nil
+}
+
+// watchAccessChannels reloads the channels when they change and applies
+// the new rules to the stored memberships of every user.
+func (m *TelegramBotModule) watchAccessChannels() {
+	reload := func(e *pbCore.RecordEvent) error {
+		if err := e.Next(); err != nil {
+			return err
+		}
+
+		ctx := core.WithCorrelationId(context.Background(), core.NewCorrelationId("access-channels"))
+		if err := m.loadAccessChannels(); err != nil {
+			core.Logger(ctx, m.Logger).Error("Failed to reload access channels", "Error", err)
+			return nil
+		}
+
+		go m.reapplyMemberships(ctx)
+		return nil
+	}
+
+	m.Ctx.App.OnRecordAfterCreateSuccess(accessChannelsCollection).BindFunc(reload)
+	m.Ctx.App.OnRecordAfterUpdateSuccess(accessChannelsCollection).BindFunc(reload)
+	m.Ctx.App.OnRecordAfterDeleteSuccess(accessChannelsCollection).BindFunc(reload)
+}
+
+func (m *TelegramBotModule) reapplyMemberships(ctx context.Context) {
+	logger := core.Logger(ctx, m.Logger)
+
+	users, err := m.users.GetAllUsers()
+	if err != nil {
+		logger.Error("Failed to get users for access channel rules", "Error", err)
+		return
+	}
+
+	for _, user := range users {
+		audits := m.applyMemberships(user, 0)
+		if len(audits) == 0 {
+			continue
+		}
+
+		if err := m.Ctx.App.SaveWithContext(ctx, user); err != nil {
+			logger.Error("Failed to apply access channel rules", "UserId", user.Id, "Error", err)
+			continue
+		}
+		for _, entry := range audits {
+			entry.Module = m.Name()
+			entry.ActorLabel = core.AuditActorSystem
+			entry.TargetId = user.Id
+			m.Ctx.Audit(entry)
+		}
+	}
+}
+
+// applyMemberships sets the membership role and premium computed by the
+// access channel rules and returns the audit entries of the changes.
+// chatId is the chat that triggered the change, 0 for rule changes.
+func (m *TelegramBotModule) applyMemberships(user *models.User, chatId int64) []core.AuditEntry {
+	role, premium := resolveMemberAccess(m.accessChannels(), user)
+
+	var audits []core.AuditEntry
+	if user.MemberRole() != role {
+		audits = append(audits, core.AuditEntry{
+			Action: core.AuditUserRoleChanged,
+			Before: map[string]any{"memberRole": user.MemberRole(), "chatId": chatId},
+			After:  map[string]any{"memberRole": role, "chatId": chatId},
+		})
+		user.SetMemberRole(role)
+	}
+	if user.MemberPremium() != premium {
+		audits = append(audits, core.AuditEntry{
+			Action: core.AuditUserPremiumChanged,
+			Before: map[string]any{"memberPremium": user.MemberPremium(), "chatId": chatId},
+			After:  map[string]any{"memberPremium": premium, "chatId": chatId},
+		})
+		user.SetMemberPremium(premium)
+	}
+
+	return audits
+}
+
+// resolveMemberAccess computes the role and premium given by the channels
+// the user is a member of. The role comes from the highest priority channel
+// giving one, the highest role wins between channels of equal priority.
+// Premium is given by any premium channel. Disabled channels are ignored
+// and the order of the channels doesn't matter.
+func resolveMemberAccess(channels []*models.AccessChannel, user *models.User) (models.UserRole, bool) {
+	role := models.RoleGuest
+	premium := false
+	rolePriority, hasRole := 0, false
+
+	for _, channel := range channels {
+		status := tele.MemberStatus(user.Membership(channel.ChatId()))
+		if !channel.Enabled() || !isMemberStatus(status) {
+			continue
+		}
+
+		channelRole := models.UserRole("")
+		switch channel.Grant() {
+		case models.AccessGrantPremium:
+			premium = true
+		case models.AccessGrantAdmin:
+			channelRole = models.RoleAdmin
+		case models.AccessGrantUser:
+			channelRole = models.RoleUser
+		}
+		if channel.PromoteAdmins() && (status == tele.Creator || status == tele.Administrator) {
+			channelRole = models.RoleAdmin
+		}
+
+		switch {
+		case channelRole == "":
+		case !hasRole || channel.Priority() > rolePriority:
+			role, rolePriority, hasRole = channelRole, channel.Priority(), true
+		case channel.Priority() == rolePriority && channelRole == models.RoleAdmin:
+			role = channelRole
+		}
+	}
+
+	return role, premium
+}
+
+// memberStatus is the stored status of a chat member, restricted members
+// are still members.
+func memberStatus(member *tele.ChatMember) string {
+	if member.Role == tele.Restricted && member.Member {
+		return string(tele.Member)
+	}
+	return string(member.Role)
+}
+
+func isMemberStatus(status tele.MemberStatus) bool {
+	return status == tele.Creator || status == tele.Administrator || status == tele.Member
+}
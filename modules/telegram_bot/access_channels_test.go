@@ -0,0 +1,171 @@
+package telegram_bot
+
+import (
+	"testing"
+
+	"github.com/docker-pet/backend/models"
+	"github.com/pocketbase/pocketbase/core"
+)
+
+type testChannel struct {
+	chatId        int64
+	grant         models.AccessGrant
+	priority      int
+	promoteAdmins bool
+	disabled      bool
+}
+
+func newTestChannels(channels []testChannel) []*models.AccessChannel {
+	collection := core.NewBaseCollection(accessChannelsCollection)
+	collection.Fields.Add(
+		&core.NumberField{Name: "chatId"},
+		&core.TextField{Name: "grant"},
+		&core.NumberField{Name: "priority"},
+		&core.BoolField{Name: "promoteAdmins"},
+		&core.BoolField{Name: "enabled"},
+	)
+
+	result := make([]*models.AccessChannel, len(channels))
+	for i, channel := range channels {
+		result[i] = &models.AccessChannel{}
+		result[i].SetProxyRecord(core.NewRecord(collection))
+		result[i].SetChatId(channel.chatId)
+		result[i].SetGrant(channel.grant)
+		result[i].SetPriority(channel.priority)
+		result[i].SetPromoteAdmins(channel.promoteAdmins)
+		result[i].SetEnabled(!channel.disabled)
+	}
+	return result
+}
+
+func newTestMember(memberships map[int64]string) *models.User {
+	collection := core.NewAuthCollection("users")
+	collection.Fields.Add(&core.JSONField{Name: "memberships"})
+
+	user := &models.User{}
+	user.SetProxyRecord(core.NewRecord(collection))
+	for chatId, status := range memberships {
+		user.SetMembership(chatId, status)
+	}
+	return user
+}
+
+func TestResolveMemberAccess(t *testing.T) {
+	tests := []struct {
+		name        string
+		channels    []testChannel
+		memberships map[int64]string
+		wantRole    models.UserRole
+		wantPremium bool
+	}{
+		{
+			name:     "no membership",
+			channels: []testChannel{{chatId: -1, grant: models.AccessGrantUser}},
+			wantRole: models.RoleGuest,
+		},
+		{
+			name:        "member",
+			channels:    []testChannel{{chatId: -1, grant: models.AccessGrantUser}},
+			memberships: map[int64]string{-1: "member"},
+			wantRole:    models.RoleUser,
+		},
+		{
+			name:        "left member",
+			channels:    []testChannel{{chatId: -1, grant: models.AccessGrantUser}},
+			memberships: map[int64]string{-1: "left"},
+			wantRole:    models.RoleGuest,
+		},
+		{
+			name:        "admin grant",
+			channels:    []testChannel{{chatId: -1, grant: models.AccessGrantAdmin}},
+			memberships: map[int64]string{-1: "member"},
+			wantRole:    models.RoleAdmin,
+		},
+		{
+			name:        "premium grant gives no role",
+			channels:    []testChannel{{chatId: -1, grant: models.AccessGrantPremium}},
+			memberships: map[int64]string{-1: "member"},
+			wantRole:    models.RoleGuest,
+			wantPremium: true,
+		},
+		{
+			name: "premium from any channel",
+			channels: []testChannel{
+				{chatId: -1, grant: models.AccessGrantUser, priority: 10},
+				{chatId: -2, grant: models.AccessGrantPremium},
+			},
+			memberships: map[int64]string{-1: "member", -2: "member"},
+			wantRole:    models.RoleUser,
+			wantPremium: true,
+		},
+		{
+			name:        "chat administrator promoted",
+			channels:    []testChannel{{chatId: -1, grant: models.AccessGrantUser, promoteAdmins: true}},
+			memberships: map[int64]string{-1: "administrator"},
+			wantRole:    models.RoleAdmin,
+		},
+		{
+			name:        "chat administrator not promoted",
+			channels:    []testChannel{{chatId: -1, grant: models.AccessGrantUser}},
+			memberships: map[int64]string{-1: "creator"},
+			wantRole:    models.RoleUser,
+		},
+		{
+			name: "disabled channels ignored",
+			channels: []testChannel{
+				{chatId: -1, grant: models.AccessGrantAdmin, disabled: true},
+				{chatId: -2, grant: models.AccessGrantPremium, disabled: true},
+			},
+			memberships: map[int64]string{-1: "member", -2: "member"},
+			wantRole:    models.RoleGuest,
+		},
+		{
+			name: "higher priority wins",
+			channels: []testChannel{
+				{chatId: -1, grant: models.AccessGrantAdmin, priority: 1},
+				{chatId: -2, grant: models.AccessGrantUser, priority: 5},
+			},
+			memberships: map[int64]string{-1: "member", -2: "member"},
+			wantRole:    models.RoleUser,
+		},
+		{
+			name: "higher priority wins in any order",
+			channels: []testChannel{
+				{chatId: -2, grant: models.AccessGrantUser, priority: 5},
+				{chatId: -1, grant: models.AccessGrantAdmin, priority: 1},
+			},
+			memberships: map[int64]string{-1: "member", -2: "member"},
+			wantRole:    models.RoleUser,
+		},
+		{
+			name: "higher role wins on equal priority",
+			channels: []testChannel{
+				{chatId: -1, grant: models.AccessGrantUser, priority: 5},
+				{chatId: -2, grant: models.AccessGrantAdmin, priority: 5},
+			},
+			memberships: map[int64]string{-1: "member", -2: "member"},
+			wantRole:    models.RoleAdmin,
+		},
+		{
+			name: "lower priority applies when not a member of the higher one",
+			channels: []testChannel{
+				{chatId: -1, grant: models.AccessGrantAdmin, priority: 5},
+				{chatId: -2, grant: models.AccessGrantUser, priority: 1},
+			},
+			memberships: map[int64]string{-1: "kicked", -2: "member"},
+			wantRole:    models.RoleUser,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			role, premium := resolveMemberAccess(newTestChannels(tt.channels), newTestMember(tt.memberships))
+			if role != tt.wantRole {
+				t.Errorf("resolveMemberAccess() role = %q, want %q", role, tt.wantRole)
+			}
+			if premium != tt.wantPremium {
+				t.Errorf("resolveMemberAccess() premium = %v, want %v", premium, tt.wantPremium)
+			}
+		})
+	}
+}
@@ -1,13 +1,14 @@
 package telegram_bot
 
 import (
-	"fmt"
+	"github.com/docker-pet/backend/core"
+	"github.com/docker-pet/backend/i18n"
 
 	tele "gopkg.in/telebot.v4"
 )
 
-func (m *TelegramBotModule) useAccessMiddleware() {
-	m.Bot.Use(func(next tele.HandlerFunc) tele.HandlerFunc {
+func (m *TelegramBotModule) useAccessMiddleware(bot *tele.Bot) {
+	bot.Use(func(next tele.HandlerFunc) tele.HandlerFunc {
 		return func(c tele.Context) error {
 			// Private chat (between user and bot)
 			if c.Chat().Type == tele.ChatPrivate {
@@ -15,15 +16,15 @@ func (m *TelegramBotModule) useAccessMiddleware() {
 			}
 
 			// Unauthorized chat check
-			if c.Chat().ID != m.appConfig.AppConfig().TelegramChannelId() && c.Chat().ID != m.appConfig.AppConfig().TelegramPremiumChannelId() {
-				m.Logger.Info(
+			if m.accessChannel(c.Chat().ID) == nil {
+				core.Logger(updateContext(c), m.Logger).Info(
 					"Telegram bot received message from unauthorized chat.",
 					"ChatId", c.Chat().ID,
 					"Title", c.Chat().Title,
 				)
 
 				c.Send(
-					"This bot is not authorized to work in this chat (<code>"+fmt.Sprint(c.Chat().ID)+"</code>).",
+					m.Ctx.I18n.T(senderLocale(c), "telegram_bot.unauthorized_chat", i18n.Args{"chatId": c.Chat().ID}),
 					&tele.SendOptions{
 						ParseMode:           tele.ModeHTML,
 						DisableNotification: true,
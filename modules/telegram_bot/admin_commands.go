@@ -0,0 +1,368 @@
+package telegram_bot
+
+import (
+	"html"
+	"strings"
+	"time"
+
+	"github.com/docker-pet/backend/core"
+	"github.com/docker-pet/backend/i18n"
+	"github.com/docker-pet/backend/models"
+	"github.com/pocketbase/dbx"
+	tele "gopkg.in/telebot.v4"
+)
+
+// adminHandler handles a command of an admin in a private chat.
+type adminHandler func(c tele.Context, admin *models.User, locale string) error
+
+// useAdminCommands registers the operational commands. Every command
+// checks the admin role of the sender and is audited with the admin as
+// the actor.
+func (m *TelegramBotModule) useAdminCommands(bot *tele.Bot) {
+	bot.Handle("/stats", m.adminCommand(m.handleStatsCommand))
+	bot.Handle("/user", m.adminCommand(m.handleUserCommand))
+	bot.Handle("/ban", m.adminCommand(m.handleBanCommand(true)))
+	bot.Handle("/unban", m.adminCommand(m.handleBanCommand(false)))
+	bot.Handle("/server", m.adminCommand(m.handleServerCommand))
+	bot.Handle("/resync", m.adminCommand(m.handleResyncCommand))
+	bot.Handle("/rotate", m.adminCommand(m.handleRotateCommand))
+}
+
+func (m *TelegramBotModule) adminCommand(handler adminHandler) tele.HandlerFunc {
+	return func(c tele.Context) error {
+		if c.Chat().Type != tele.ChatPrivate || c.Sender() == nil {
+			return nil
+		}
+
+		locale := senderLocale(c)
+		admin, err := m.users.GetAdminByTelegramId(c.Sender().ID)
+		if err != nil {
+			core.Logger(updateContext(c), m.Logger).Warn(
+				"Rejected admin command",
+				"Command", c.Text(),
+				"TelegramId", c.Sender().ID,
+				"Error", err,
+			)
+			return c.Send(m.Ctx.I18n.T(locale, "telegram_bot.admin.forbidden"))
+		}
+
+		return handler(c, admin, locale)
+	}
+}
+
+// auditCommand records a bot command, the sender is the actor.
+func (m *TelegramBotModule) auditCommand(actor *models.User, entry core.AuditEntry) {
+	entry.Module = m.Name()
+	entry.ActorId = actor.Id
+	entry.ActorLabel = core.AuditActorTelegram
+	m.Ctx.Audit(entry)
+}
+
+// failed logs the error and tells the admin to look at the logs.
+func (m *TelegramBotModule) failed(c tele.Context, locale string, message string, err error) error {
+	core.Logger(updateContext(c), m.Logger).Error(message, "Command", c.Text(), "Error", err)
+	return m.reply(c, locale, "telegram_bot.admin.failed", nil)
+}
+
+func (m *TelegramBotModule) handleStatsCommand(c tele.Context, admin *models.User, locale string) error {
+	counts := map[string]dbx.Expression{
+		"total":   nil,
+		"guests":  dbx.HashExp{"role": models.RoleGuest},
+		"users":   dbx.HashExp{"role": models.RoleUser},
+		"admins":  dbx.HashExp{"role": models.RoleAdmin},
+		"premium": dbx.HashExp{"premium": true},
+		"banned":  dbx.HashExp{"banned": true},
+	}
+
+	args := i18n.Args{}
+	for name, exp := range counts {
+		var exprs []dbx.Expression
+		if exp != nil {
+			exprs = append(exprs, exp)
+		}
+		count, err := m.users.CountUsers(exprs...)
+		if err != nil {
+			return m.failed(c, locale, "Failed to count users", err)
+		}
+		args[name] = count
+	}
+
+	lines := []string{m.Ctx.I18n.T(locale, "telegram_bot.admin.stats", args)}
+	if m.outline != nil {
+		servers, err := m.outline.GetAllActiveServers()
+		if err != nil {
+			return m.failed(c, locale, "Failed to get active Outline servers", err)
+		}
+
+		lines = append(lines, m.Ctx.I18n.T(locale, "telegram_bot.admin.stats.servers", i18n.Args{"count": len(servers)}))
+		for _, server := range servers {
+			lines = append(lines, m.Ctx.I18n.T(locale, "telegram_bot.admin.stats.server", i18n.Args{
+				"slug": html.EscapeString(server.Slug()),
+				"sync": m.caddySyncText(locale, server.Id),
+			}))
+		}
+	}
+
+	m.auditCommand(admin, core.AuditEntry{
+		Action: core.AuditBotAdminCommand,
+		After:  map[string]any{"command": "stats"},
+	})
+
+	return c.Send(strings.Join(lines, "\n"), &tele.SendOptions{ParseMode: tele.ModeHTML})
+}
+
+func (m *TelegramBotModule) caddySyncText(locale string, serverId string) string {
+	status, ok := m.outline.CaddySyncStatus(serverId)
+	switch {
+	case !ok:
+		return m.Ctx.I18n.T(locale, "telegram_bot.admin.sync.never")
+	case status.LastError != nil:
+		return m.Ctx.I18n.T(locale, "telegram_bot.admin.sync.failed", i18n.Args{"time": formatTime(status.LastAttempt)})
+	default:
+		return m.Ctx.I18n.T(locale, "telegram_bot.admin.sync.ok", i18n.Args{"time": formatTime(status.LastSuccess)})
+	}
+}
+
+func (m *TelegramBotModule) handleUserCommand(c tele.Context, admin *models.User, locale string) error {
+	user, ok, err := m.commandTarget(c, locale, "telegram_bot.admin.usage.user")
+	if !ok {
+		return err
+	}
+
+	now := time.Now()
+	yesNo := func(value bool) string {
+		if value {
+			return m.Ctx.I18n.T(locale, "telegram_bot.yes")
+		}
+		return m.Ctx.I18n.T(locale, "telegram_bot.no")
+	}
+	orDash := func(value string) string {
+		if value == "" {
+			return "—"
+		}
+		return html.EscapeString(value)
+	}
+
+	banned := yesNo(user.Banned())
+	if user.Banned() && user.BanReason() != "" {
+		banned += " (" + html.EscapeString(user.BanReason()) + ")"
+	}
+
+	lines := []string{m.Ctx.I18n.T(locale, "telegram_bot.admin.user", i18n.Args{
+		"user":          userLabel(user),
+		"id":            user.Id,
+		"telegramId":    user.TelegramId(),
+		"role":          user.Role(),
+		"memberRole":    user.MemberRole(),
+		"roleOverride":  orDash(string(user.RoleOverride())),
+		"premium":       yesNo(user.Premium()),
+		"premiumUntil":  orDash(formatDateTime(user.PremiumUntil().Time())),
+		"accessExpires": orDash(formatDateTime(user.AccessExpires(now).Time())),
+		"banned":        banned,
+	})}
+
+	if m.outline != nil {
+		server := "—"
+		if user.OutlineServer() != "" {
+			if outlineServer, err := m.outline.GetServerById(user.OutlineServer()); err == nil {
+				server = html.EscapeString(outlineServer.Slug())
+			}
+		}
+		lines = append(lines, m.Ctx.I18n.T(locale, "telegram_bot.admin.user.outline", i18n.Args{
+			"server":  server,
+			"prefix":  yesNo(user.OutlinePrefixEnabled()),
+			"reverse": yesNo(user.OutlineReverseServerEnabled()),
+		}))
+	}
+
+	if m.lampa != nil {
+		lampaState := "telegram_bot.admin.user.lampa.none"
+		if lampaUser, err := m.lampa.GetLampaUserByUserId(user.Id); err == nil {
+			lampaState = "telegram_bot.admin.user.lampa.active"
+			if lampaUser.Disabled() {
+				lampaState = "telegram_bot.admin.user.lampa.disabled"
+			}
+		}
+		lines = append(lines, m.Ctx.I18n.T(locale, lampaState))
+	}
+
+	m.auditCommand(admin, core.AuditEntry{
+		Action:   core.AuditBotAdminCommand,
+		TargetId: user.Id,
+		After:    map[string]any{"command": "user"},
+	})
+
+	return c.Send(strings.Join(lines, "\n"), &tele.SendOptions{ParseMode: tele.ModeHTML})
+}
+
+func (m *TelegramBotModule) handleBanCommand(ban bool) adminHandler {
+	usageKey := "telegram_bot.admin.usage.unban"
+	if ban {
+		usageKey = "telegram_bot.admin.usage.ban"
+	}
+
+	return func(c tele.Context, admin *models.User, locale string) error {
+		user, ok, err := m.commandTarget(c, locale, usageKey)
+		if !ok {
+			return err
+		}
+
+		// Admins can't lock themselves out
+		if user.Id == admin.Id {
+			return m.reply(c, locale, "telegram_bot.admin.own_access", nil)
+		}
+
+		reason := ""
+		if ban {
+			reason = strings.Join(c.Args()[1:], " ")
+		}
+
+		before := map[string]any{"banned": user.Banned(), "banReason": user.BanReason()}
+		user.SetBanned(ban, reason)
+		if err := m.Ctx.App.SaveWithContext(updateContext(c), user); err != nil {
+			return m.failed(c, locale, "Failed to save user", err)
+		}
+
+		action, key := core.AuditUserUnbanned, "telegram_bot.admin.unbanned"
+		after := map[string]any{"banned": false}
+		if ban {
+			action, key = core.AuditUserBanned, "telegram_bot.admin.banned"
+			after = map[string]any{"banned": true, "banReason": reason}
+		}
+		m.auditCommand(admin, core.AuditEntry{
+			Action:   action,
+			TargetId: user.Id,
+			Before:   before,
+			After:    after,
+		})
+
+		return m.reply(c, locale, key, i18n.Args{"user": userLabel(user)})
+	}
+}
+
+func (m *TelegramBotModule) handleServerCommand(c tele.Context, admin *models.User, locale string) error {
+	args := c.Args()
+	if len(args) != 2 || (args[0] != "enable" && args[0] != "disable") {
+		return m.reply(c, locale, "telegram_bot.admin.usage.server", nil)
+	}
+
+	server, ok, err := m.commandServer(c, locale, args[1])
+	if !ok {
+		return err
+	}
+
+	// Saving reconfigures Caddy and the generated configs of the server
+	enabled := args[0] == "enable"
+	before := server.Enabled()
+	server.SetEnabled(enabled)
+	if err := m.Ctx.App.SaveWithContext(updateContext(c), server); err != nil {
+		return m.failed(c, locale, "Failed to save Outline server", err)
+	}
+
+	action, key := core.AuditOutlineServerDisabled, "telegram_bot.admin.server_disabled"
+	if enabled {
+		action, key = core.AuditOutlineServerEnabled, "telegram_bot.admin.server_enabled"
+	}
+	m.auditCommand(admin, core.AuditEntry{
+		Action: action,
+		Before: map[string]any{"slug": server.Slug(), "enabled": before},
+		After:  map[string]any{"slug": server.Slug(), "enabled": enabled},
+	})
+
+	return m.reply(c, locale, key, i18n.Args{"slug": html.EscapeString(server.Slug())})
+}
+
+func (m *TelegramBotModule) handleResyncCommand(c tele.Context, admin *models.User, locale string) error {
+	if len(c.Args()) != 1 {
+		return m.reply(c, locale, "telegram_bot.admin.usage.resync", nil)
+	}
+
+	server, ok, err := m.commandServer(c, locale, c.Args()[0])
+	if !ok {
+		return err
+	}
+
+	syncErr := m.outline.ResyncServer(updateContext(c), server.Id)
+	m.auditCommand(admin, core.AuditEntry{
+		Action: core.AuditOutlineServerResynced,
+		After:  map[string]any{"slug": server.Slug(), "success": syncErr == nil},
+	})
+
+	if syncErr != nil {
+		core.Logger(updateContext(c), m.Logger).Warn("Failed to resync Outline server", "Slug", server.Slug(), "Error", syncErr)
+		return m.reply(c, locale, "telegram_bot.admin.resync_failed", i18n.Args{"slug": html.EscapeString(server.Slug())})
+	}
+	return m.reply(c, locale, "telegram_bot.admin.resynced", i18n.Args{"slug": html.EscapeString(server.Slug())})
+}
+
+func (m *TelegramBotModule) handleRotateCommand(c tele.Context, admin *models.User, locale string) error {
+	user, ok, err := m.commandTarget(c, locale, "telegram_bot.admin.usage.rotate")
+	if !ok {
+		return err
+	}
+
+	// Saving publishes OutlineTokenRotated, which reconfigures the servers
+	user.GenerateOutlineToken()
+	if err := m.Ctx.App.SaveWithContext(updateContext(c), user); err != nil {
+		return m.failed(c, locale, "Failed to save user", err)
+	}
+
+	// Never store the token itself
+	m.auditCommand(admin, core.AuditEntry{
+		Action:   core.AuditOutlineTokenRotated,
+		TargetId: user.Id,
+	})
+
+	return m.reply(c, locale, "telegram_bot.admin.rotated", i18n.Args{"user": userLabel(user)})
+}
+
+// commandTarget resolves the user given as the first argument. When ok is
+// false the admin has already been answered and err is the send error.
+func (m *TelegramBotModule) commandTarget(c tele.Context, locale string, usageKey string) (user *models.User, ok bool, err error) {
+	if len(c.Args()) == 0 {
+		return nil, false, m.reply(c, locale, usageKey, nil)
+	}
+
+	user, err = m.users.GetUserByReference(c.Args()[0])
+	if err != nil {
+		return nil, false, m.reply(c, locale, "telegram_bot.admin.user_not_found", nil)
+	}
+	return user, true, nil
+}
+
+// commandServer resolves the outline server by its slug, see commandTarget.
+func (m *TelegramBotModule) commandServer(c tele.Context, locale string, slug string) (server *models.OutlineServer, ok bool, err error) {
+	if m.outline == nil {
+		return nil, false, m.reply(c, locale, "telegram_bot.admin.outline_disabled", nil)
+	}
+
+	server, err = m.outline.GetServerBySlug(slug)
+	if err != nil {
+		return nil, false, m.reply(c, locale, "telegram_bot.admin.server_not_found", i18n.Args{"slug": html.EscapeString(slug)})
+	}
+	return server, true, nil
+}
+
+// userLabel is the escaped name and @username of the user.
+func userLabel(user *models.User) string {
+	label := user.Name()
+	if label == "" {
+		label = user.Id
+	}
+	if user.TelegramUsername() != "" {
+		label += " (@" + user.TelegramUsername() + ")"
+	}
+	return html.EscapeString(label)
+}
+
+func formatTime(t time.Time) string {
+	return t.UTC().Format("2006-01-02 15:04:05 UTC")
+}
+
+// formatDateTime is empty for the zero time.
+func formatDateTime(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.UTC().Format("2006-01-02 15:04 UTC")
+}
@@ -1,55 +1,225 @@
 package telegram_bot
 
 import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
 	"fmt"
+	"net"
 	"net/http"
+	"strings"
 
 	"github.com/pocketbase/pocketbase/core"
 	tele "gopkg.in/telebot.v4"
 )
 
-func (m *TelegramBotModule) newBot(e *core.ServeEvent) (*tele.Bot, error) {
-	endpointPath := fmt.Sprintf("/api/telegram_bot/%s", m.appConfig.AppConfig().TelegramBotToken())
-	endpointUrl := fmt.Sprintf("https://%s%s", m.appConfig.AppConfig().AppDomain(), endpointPath)
+// Poller modes of the bot
+const (
+	PollerWebhook  = "webhook"  // Updates are pushed to the webhook endpoint
+	PollerLongPoll = "longpoll" // Updates are pulled with getUpdates
+	PollerAuto     = "auto"     // Webhook for a public app domain, long polling otherwise
+)
 
-	// Webhook
-	webhook := &tele.Webhook{
-		Endpoint: &tele.WebhookEndpoint{
-			PublicURL: endpointUrl,
-		},
+var allowedUpdates = []string{
+	"my_chat_member",
+	"chat_member",
+	"chat_join_request",
+	"message",
+}
 
-		Listen: "",
+// pollerMode resolves the configured mode for the current app domain.
+func (m *TelegramBotModule) pollerMode() string {
+	if m.Config.PollerMode != PollerAuto {
+		return m.Config.PollerMode
+	}
+	if isPublicDomain(m.appConfig.AppConfig().AppDomain()) {
+		return PollerWebhook
+	}
+	return PollerLongPoll
+}
 
-		AllowedUpdates: []string{
-			"my_chat_member",
-			"chat_member",
-			"chat_join_request",
-			"message",
-		},
+// newBot creates a bot for the current app config with all handlers. In
+// webhook mode its webhook points to the endpoint served by
+// registerWebhookEndpoint, in long polling mode the webhook is deleted and
+// returned so it can be restored when the bot stops.
+func (m *TelegramBotModule) newBot(mode string) (*tele.Bot, *webhookPoller, *tele.Webhook, error) {
+	token := m.appConfig.AppConfig().TelegramBotToken()
+	countUpdate := func(update *tele.Update) {
+		m.metrics.updates.WithLabelValues(updateType(update)).Inc()
+	}
+
+	// Poller
+	var poller tele.Poller
+	var hook *webhookPoller
+	if mode == PollerLongPoll {
+		poller = tele.NewMiddlewarePoller(&tele.LongPoller{
+			Timeout:        m.Config.LongPollTimeout,
+			AllowedUpdates: allowedUpdates,
+		}, func(update *tele.Update) bool {
+			countUpdate(update)
+			return true
+		})
+	} else {
+		hook = &webhookPoller{onUpdate: countUpdate}
+		poller = hook
 	}
 
 	// Bot
 	bot, err := tele.NewBot(tele.Settings{
-		Token:  m.appConfig.AppConfig().TelegramBotToken(),
-		Poller: webhook,
+		URL:    m.appConfig.Config.TelegramApiUrl,
+		Token:  token,
+		Poller: poller,
 	})
 
 	if err != nil {
-		return nil, fmt.Errorf("failed to initialize Telegram bot: %w", err)
+		return nil, nil, nil, fmt.Errorf("failed to initialize Telegram bot: %w", err)
 	}
 
-	// Webhook Pooler
-	_, err = bot.Webhook()
-	if err != nil {
-		return nil, fmt.Errorf("failed to initialize Telegram bot webhook: %w", err)
+	// Webhook
+	var previousWebhook *tele.Webhook
+	if mode == PollerLongPoll {
+		if current, err := bot.Webhook(); err == nil && current.Listen != "" {
+			previousWebhook = current
+		}
+		if err := bot.RemoveWebhook(); err != nil {
+			return nil, nil, nil, fmt.Errorf("failed to delete Telegram bot webhook: %w", err)
+		}
+	} else {
+		webhook := &tele.Webhook{
+			Endpoint: &tele.WebhookEndpoint{
+				PublicURL: webhookUrl(m.appConfig.AppConfig().AppDomain(), token),
+			},
+			AllowedUpdates: allowedUpdates,
+			SecretToken:    webhookSecret(token),
+		}
+		if err := bot.SetWebhook(webhook); err != nil {
+			return nil, nil, nil, fmt.Errorf("failed to initialize Telegram bot webhook: %w", err)
+		}
+	}
+
+	m.registerHandlers(bot)
+
+	// Initialized
+	return bot, hook, previousWebhook, nil
+}
+
+// registerHandlers is shared by both poller modes.
+func (m *TelegramBotModule) registerHandlers(bot *tele.Bot) {
+	m.useCorrelationMiddleware(bot)
+	m.useAccessMiddleware(bot)
+	m.useOnChatMember(bot)
+	m.useOnChatJoinRequest(bot)
+	m.useOnMyChatMember(bot)
+	m.useStartCommand(bot)
+	m.useAdminCommands(bot)
+	m.useUserCommands(bot)
+}
+
+// restoreWebhook sets the webhook deleted by a long polling bot again. A
+// webhook set by this module earlier points to the app domain it had then,
+// so its URL is derived from the current app config instead, and it is not
+// restored when the domain is no longer public or the token has changed.
+// Telegram doesn't return the secret token, it is derived again for the
+// webhooks of this backend.
+func (m *TelegramBotModule) restoreWebhook(bot *tele.Bot, webhook *tele.Webhook, own bool) error {
+	restored := &tele.Webhook{
+		Endpoint:       &tele.WebhookEndpoint{PublicURL: webhook.Listen},
+		MaxConnections: webhook.MaxConnections,
+		AllowedUpdates: webhook.AllowedUpdates,
+		IP:             webhook.IP,
+	}
+	if own {
+		appConfig := m.appConfig.AppConfig()
+		if !isPublicDomain(appConfig.AppDomain()) || appConfig.TelegramBotToken() != bot.Token {
+			return nil
+		}
+		restored.Endpoint.PublicURL = webhookUrl(appConfig.AppDomain(), bot.Token)
+	}
+	if strings.HasSuffix(restored.Endpoint.PublicURL, "/api/telegram_bot/webhook/"+webhookId(bot.Token)) {
+		restored.SecretToken = webhookSecret(bot.Token)
 	}
+	return bot.SetWebhook(restored)
+}
 
-	// HTTP Endpoint
-	e.Router.POST(endpointPath, func(ctx *core.RequestEvent) error {
-		webhook.ServeHTTP(ctx.Response, ctx.Request)
+// isPublicDomain reports whether Telegram can reach the domain, local names
+// and private addresses can't receive webhooks.
+func isPublicDomain(domain string) bool {
+	host := domain
+	if h, _, err := net.SplitHostPort(domain); err == nil {
+		host = h
+	}
+	host = strings.ToLower(strings.TrimSuffix(host, "."))
+	if host == "" || host == "localhost" {
+		return false
+	}
+
+	for _, suffix := range []string{".localhost", ".local", ".internal", ".lan", ".home.arpa"} {
+		if strings.HasSuffix(host, suffix) {
+			return false
+		}
+	}
+
+	if ip := net.ParseIP(host); ip != nil {
+		return !ip.IsLoopback() && !ip.IsPrivate() && !ip.IsLinkLocalUnicast() && !ip.IsUnspecified()
+	}
+	return strings.Contains(host, ".")
+}
+
+// registerWebhookEndpoint serves the webhook of whichever bot is current, so
+// the bot can be re-created without touching the router. The path holds a
+// hash of the token instead of the token itself, and requests without the
+// secret token set with the webhook are rejected before they are decoded.
+func (m *TelegramBotModule) registerWebhookEndpoint(e *core.ServeEvent) {
+	e.Router.POST("/api/telegram_bot/webhook/{id}", func(ctx *core.RequestEvent) error {
+		m.mu.RLock()
+		poller, token := m.poller, m.botToken
+		m.mu.RUnlock()
+
+		if poller == nil || !constantTimeEqual(ctx.Request.PathValue("id"), webhookId(token)) {
+			m.metrics.rejected.WithLabelValues("path").Inc()
+			return ctx.NotFoundError("", nil)
+		}
+
+		if !constantTimeEqual(ctx.Request.Header.Get(webhookSecretHeader), webhookSecret(token)) {
+			m.metrics.rejected.WithLabelValues("secret").Inc()
+			m.Logger.Warn(
+				"Rejected Telegram webhook request without a valid secret token",
+				"RemoteIp", ctx.RealIP(),
+			)
+			return ctx.UnauthorizedError("", nil)
+		}
+
+		poller.ServeHTTP(ctx.Response, ctx.Request)
 		return ctx.JSON(http.StatusOK, map[string]bool{"ok": true})
 	})
+}
 
-	// Initialized
-	return bot, nil
+const webhookSecretHeader = "X-Telegram-Bot-Api-Secret-Token"
+
+// webhookUrl is the public URL of the webhook endpoint for the token.
+func webhookUrl(domain string, token string) string {
+	return fmt.Sprintf("https://%s/api/telegram_bot/webhook/%s", domain, webhookId(token))
+}
+
+// webhookId identifies the webhook in its path without revealing the token.
+func webhookId(token string) string {
+	return deriveFromToken(token, "webhook-path")[:32]
+}
+
+// webhookSecret is the secret_token Telegram sends with every update. It is
+// derived from the token, so it survives restarts and can be restored after
+// long polling.
+func webhookSecret(token string) string {
+	return deriveFromToken(token, "webhook-secret")
+}
+
+func deriveFromToken(token string, purpose string) string {
+	mac := hmac.New(sha256.New, []byte(token))
+	mac.Write([]byte(purpose))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+func constantTimeEqual(a string, b string) bool {
+	return subtle.ConstantTimeCompare([]byte(a), []byte(b)) == 1
 }
@@ -0,0 +1,118 @@
+package telegram_bot
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/docker-pet/backend/core"
+	"github.com/docker-pet/backend/helpers"
+	tele "gopkg.in/telebot.v4"
+)
+
+const botReloadTimeout = 30 * time.Second
+
+// setupBot creates the bot for the current app config and makes it current.
+// The bot is not started.
+func (m *TelegramBotModule) setupBot() (*tele.Bot, error) {
+	mode := m.pollerMode()
+	bot, poller, previousWebhook, err := m.newBot(mode)
+
+	m.mu.Lock()
+	m.initError = err
+	if err == nil {
+		m.Bot = bot
+		m.poller = poller
+		m.botToken = bot.Token
+		m.mode = mode
+		m.previousWebhook = previousWebhook
+		if mode == PollerWebhook {
+			m.ownWebhookUrl = webhookUrl(m.appConfig.AppConfig().AppDomain(), bot.Token)
+		}
+	}
+	m.mu.Unlock()
+
+	if err != nil {
+		return nil, err
+	}
+
+	m.appConfig.SetBotUsername(bot.Me.Username)
+	m.Logger.Info("Telegram bot poller configured", "Mode", mode, "ApiUrl", m.appConfig.Config.TelegramApiUrl)
+	return bot, nil
+}
+
+// stopBot detaches the current bot and waits until it is stopped. The
+// webhook deleted by a long polling bot is restored.
+func (m *TelegramBotModule) stopBot(ctx context.Context) (*tele.Bot, string, error) {
+	m.mu.Lock()
+	bot, mode, previousWebhook, ownWebhookUrl := m.Bot, m.mode, m.previousWebhook, m.ownWebhookUrl
+	m.Bot = nil
+	m.poller = nil
+	m.botToken = ""
+	m.mode = ""
+	m.previousWebhook = nil
+	m.mu.Unlock()
+
+	if bot == nil {
+		return nil, "", nil
+	}
+	if err := helpers.WaitContext(ctx, bot.Stop); err != nil {
+		return bot, mode, err
+	}
+
+	if previousWebhook != nil {
+		if err := m.restoreWebhook(bot, previousWebhook, previousWebhook.Listen == ownWebhookUrl); err != nil {
+			return bot, mode, fmt.Errorf("failed to restore Telegram bot webhook: %w", err)
+		}
+	}
+	return bot, mode, nil
+}
+
+func (m *TelegramBotModule) currentBot() *tele.Bot {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.Bot
+}
+
+// watchConfigChanges re-creates the bot and its webhook in place when the
+// token or the domain of the webhook changes.
+func (m *TelegramBotModule) watchConfigChanges() {
+	core.Subscribe(m.Ctx, func(event core.AppConfigChanged) error {
+		if !event.Changed("telegramBotToken", "appDomain") {
+			return nil
+		}
+
+		tokenChanged := event.Changed("telegramBotToken")
+		go m.reloadBot(tokenChanged)
+		return nil
+	})
+}
+
+func (m *TelegramBotModule) reloadBot(tokenChanged bool) {
+	m.reloadMu.Lock()
+	defer m.reloadMu.Unlock()
+
+	ctx, cancel := context.WithTimeout(context.Background(), botReloadTimeout)
+	defer cancel()
+
+	oldBot, oldMode, err := m.stopBot(ctx)
+	if err != nil {
+		m.Logger.Warn("Failed to stop Telegram bot before reload", "Error", err)
+	}
+
+	// The old bot would keep sending updates to an endpoint that rejects them
+	if oldBot != nil && oldMode == PollerWebhook && tokenChanged {
+		if err := oldBot.RemoveWebhook(); err != nil {
+			m.Logger.Warn("Failed to remove webhook of the previous Telegram bot", "Error", err)
+		}
+	}
+
+	bot, err := m.setupBot()
+	if err != nil {
+		m.Logger.Warn("Failed to reload Telegram bot", "Error", err)
+		return
+	}
+
+	go bot.Start()
+	m.Logger.Info("Telegram bot reloaded", "BotUsername", bot.Me.Username)
+}
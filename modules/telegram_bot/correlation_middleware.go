@@ -0,0 +1,30 @@
+package telegram_bot
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/docker-pet/backend/core"
+	tele "gopkg.in/telebot.v4"
+)
+
+const updateContextKey = "context"
+
+// useCorrelationMiddleware ties everything done for an update to its
+// update_id, handlers get the context with updateContext.
+func (m *TelegramBotModule) useCorrelationMiddleware(bot *tele.Bot) {
+	bot.Use(func(next tele.HandlerFunc) tele.HandlerFunc {
+		return func(c tele.Context) error {
+			id := fmt.Sprintf("tg-%d", c.Update().ID)
+			c.Set(updateContextKey, core.WithCorrelationId(context.Background(), id))
+			return next(c)
+		}
+	})
+}
+
+func updateContext(c tele.Context) context.Context {
+	if ctx, ok := c.Get(updateContextKey).(context.Context); ok {
+		return ctx
+	}
+	return context.Background()
+}
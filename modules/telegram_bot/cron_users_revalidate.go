@@ -1,80 +1,88 @@
 package telegram_bot
 
 import (
+	"context"
 	"strings"
 	"time"
 
-	"github.com/docker-pet/backend/models"
+	"github.com/docker-pet/backend/core"
 	"github.com/pocketbase/dbx"
 	"github.com/pocketbase/pocketbase/tools/types"
+	"github.com/prometheus/client_golang/prometheus"
 	tele "gopkg.in/telebot.v4"
 )
 
+const cronUsersRevalidateJobId = "telegram_bot_channel_users"
+
 func (m *TelegramBotModule) useUsersRevalidateCron() {
-	m.Ctx.App.Cron().MustAdd("telegram_bot_channel_users", m.Config.CronUserSyncExpression, func() {
-		if m.Bot == nil {
-			m.Logger.Warn("Telegram bot is not initialized, skipping user sync")
+	m.Ctx.App.Cron().MustAdd(cronUsersRevalidateJobId, m.Config.CronUserSyncExpression, func() {
+		ctx := core.WithCorrelationId(context.Background(), core.NewCorrelationId("cron"))
+		logger := core.Logger(ctx, m.Logger)
+
+		bot := m.currentBot()
+		if bot == nil {
+			logger.Warn("Telegram bot is not initialized, skipping user sync")
 			return
 		}
 
+		timer := prometheus.NewTimer(m.metrics.syncDuration)
+		defer timer.ObserveDuration()
+
 		users, err := m.users.FindUsersByFilter("synced < {:synced}", "+synced", m.Config.CronUsersPerSync, 0, dbx.Params{
 			"synced": time.Now().Add(-m.Config.CronUserSyncInterval).Format(time.RFC3339),
 		})
 
 		if err != nil {
-			m.Logger.Warn("Failed to fetch users for Telegram bot channel update:", "Err", err)
+			logger.Warn("Failed to fetch users for Telegram bot channel update:", "Err", err)
 			return
 		}
 
-		// Check if has access to the main channel
-		mainChannel, err := m.Bot.ChatByID(m.appConfig.AppConfig().TelegramChannelId())
-		if err != nil {
-			m.Logger.Warn("Telegram bot is not a member of the main channel, skipping user sync")
+		channels := m.accessChannels()
+		if len(channels) == 0 {
+			logger.Warn("No access channels configured, skipping user sync")
 			return
 		}
 
-		// Check if has access to the premium channel
-		premiumChannel, err := m.Bot.ChatByID(m.appConfig.AppConfig().TelegramPremiumChannelId())
-		if err != nil && premiumChannel != nil {
-			m.Logger.Warn("Telegram bot is not a member of the premium channel, skipping premium user sync")
-		}
-
 		// Fetch and update user records
 		for _, user := range users {
 			user.SetSynced(types.NowDateTime())
 			userQuery := &tele.User{ID: user.TelegramId()}
 
-			// Main channel
-			mainMember, err := m.Bot.ChatMemberOf(mainChannel, userQuery)
-			if err == nil {
-				updatedUser, err := m.handleChatMember(mainMember, mainChannel.ID)
+			for _, channel := range channels {
+				member, err := bot.ChatMemberOf(&tele.Chat{ID: channel.ChatId()}, userQuery)
 				if err == nil {
-					user = updatedUser
-				}
-			} else if strings.Contains(err.Error(), "PARTICIPANT_ID_INVALID") {
-				user.SetRole(models.RoleGuest)
-			}
-
-			// Premium channel
-			if premiumChannel != nil {
-				premiumMember, err := m.Bot.ChatMemberOf(premiumChannel, userQuery)
-				if err == nil {
-					updatedUser, err := m.handleChatMember(premiumMember, premiumChannel.ID)
-					if err == nil {
-						user = updatedUser
-					}
+					user.SetMembership(channel.ChatId(), memberStatus(member))
+					syncProfile(user, member.User)
 				} else if strings.Contains(err.Error(), "PARTICIPANT_ID_INVALID") {
-					user.SetPremium(false)
+					user.SetMembership(channel.ChatId(), string(tele.Left))
+				} else {
+					logger.Warn(
+						"Failed to get chat member for access channel",
+						"ChatId", channel.ChatId(),
+						"UserId", user.Id,
+						"Error", err,
+					)
 				}
 			}
+			audits := m.applyMemberships(user, 0)
 
 			// Save user record if needed
-			if err := m.Ctx.App.Save(user); err != nil {
-				m.Logger.Error(
+			if err := m.Ctx.App.SaveWithContext(ctx, user); err != nil {
+				logger.Error(
 					"Failed to save user record after Telegram bot channel update",
 					"Error", err,
 					"UserId", user.Id,
 				)
+				m.metrics.usersSynced.WithLabelValues("failed").Inc()
+				continue
+			}
+			m.metrics.usersSynced.WithLabelValues("ok").Inc()
+
+			for _, entry := range audits {
+				entry.Module = m.Name()
+				entry.ActorLabel = core.AuditActorTelegram
+				entry.TargetId = user.Id
+				m.Ctx.Audit(entry)
 			}
 		}
 	})
@@ -0,0 +1,21 @@
+package telegram_bot
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+func (m *TelegramBotModule) HealthCheck(ctx context.Context) error {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	if m.Bot != nil {
+		return nil
+	}
+
+	if m.initError != nil {
+		return fmt.Errorf("telegram bot is not initialized: %w", m.initError)
+	}
+	return errors.New("telegram bot is not initialized")
+}
@@ -0,0 +1,23 @@
+package telegram_bot
+
+import (
+	"github.com/docker-pet/backend/i18n"
+	tele "gopkg.in/telebot.v4"
+)
+
+// senderLocale is the language code of the update sender, empty for
+// updates without one (e.g. channel posts), which use the fallback locale.
+func senderLocale(c tele.Context) string {
+	if c.Sender() == nil {
+		return ""
+	}
+	return c.Sender().LanguageCode
+}
+
+// reply sends an HTML message, values in args must be escaped.
+func (m *TelegramBotModule) reply(c tele.Context, locale string, key string, args i18n.Args) error {
+	return c.Send(m.Ctx.I18n.T(locale, key, args), &tele.SendOptions{
+		ParseMode:             tele.ModeHTML,
+		DisableWebPagePreview: true,
+	})
+}
@@ -0,0 +1,66 @@
+package telegram_bot
+
+import (
+	"github.com/docker-pet/backend/core"
+	"github.com/prometheus/client_golang/prometheus"
+	tele "gopkg.in/telebot.v4"
+)
+
+type botMetrics struct {
+	updates      *prometheus.CounterVec
+	rejected     *prometheus.CounterVec
+	usersSynced  *prometheus.CounterVec
+	syncDuration prometheus.Histogram
+}
+
+func (m *TelegramBotModule) registerMetrics() {
+	m.metrics = &botMetrics{
+		updates: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: core.MetricsNamespace,
+			Subsystem: m.Name(),
+			Name:      "updates_total",
+			Help:      "Telegram webhook updates by type.",
+		}, []string{"type"}),
+		rejected: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: core.MetricsNamespace,
+			Subsystem: m.Name(),
+			Name:      "webhook_rejected_total",
+			Help:      "Webhook requests rejected before decoding by reason (path, secret).",
+		}, []string{"reason"}),
+		usersSynced: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: core.MetricsNamespace,
+			Subsystem: m.Name(),
+			Name:      "users_synced_total",
+			Help:      "Users revalidated by the sync cron job by result.",
+		}, []string{"result"}),
+		syncDuration: prometheus.NewHistogram(prometheus.HistogramOpts{
+			Namespace: core.MetricsNamespace,
+			Subsystem: m.Name(),
+			Name:      "users_sync_duration_seconds",
+			Help:      "Duration of the users sync cron job runs.",
+			Buckets:   []float64{.1, .5, 1, 2.5, 5, 10, 30, 60},
+		}),
+	}
+
+	m.Ctx.Metrics.MustRegister(m.metrics.updates, m.metrics.rejected, m.metrics.usersSynced, m.metrics.syncDuration)
+}
+
+// updateType returns the name of the update field that is set.
+func updateType(update *tele.Update) string {
+	switch {
+	case update.Message != nil:
+		return "message"
+	case update.EditedMessage != nil:
+		return "edited_message"
+	case update.Callback != nil:
+		return "callback_query"
+	case update.ChatMember != nil:
+		return "chat_member"
+	case update.MyChatMember != nil:
+		return "my_chat_member"
+	case update.ChatJoinRequest != nil:
+		return "chat_join_request"
+	default:
+		return "other"
+	}
+}
@@ -0,0 +1,38 @@
+package telegram_bot
+
+import (
+	"math"
+	"time"
+
+	"github.com/docker-pet/backend/core"
+	"github.com/docker-pet/backend/i18n"
+	"github.com/docker-pet/backend/models"
+	tele "gopkg.in/telebot.v4"
+)
+
+// notifySubscriptions warns users in private messages about the upcoming
+// and the past end of their subscription periods.
+func (m *TelegramBotModule) notifySubscriptions() {
+	core.Subscribe(m.Ctx, func(event core.SubscriptionExpiring) error {
+		days := int(math.Ceil(time.Until(event.Ends).Hours() / 24))
+		return m.sendToUser(event.User, "telegram_bot.subscription.expiring."+string(event.Kind), i18n.Args{
+			"count": max(days, 1),
+			"date":  event.Ends.UTC().Format("2006-01-02 15:04 UTC"),
+		})
+	})
+
+	core.Subscribe(m.Ctx, func(event core.SubscriptionExpired) error {
+		return m.sendToUser(event.User, "telegram_bot.subscription.expired."+string(event.Kind), nil)
+	})
+}
+
+func (m *TelegramBotModule) sendToUser(user *models.User, key string, args i18n.Args) error {
+	bot := m.currentBot()
+	if bot == nil {
+		m.Logger.Warn("Telegram bot is not initialized, skipping notification", "UserId", user.Id, "Key", key)
+		return nil
+	}
+
+	_, err := bot.Send(&tele.User{ID: user.TelegramId()}, m.Ctx.I18n.T(user.Language(), key, args))
+	return err
+}
@@ -1,26 +1,31 @@
 package telegram_bot
 
 import (
+	"github.com/docker-pet/backend/core"
+	"github.com/docker-pet/backend/models"
 	tele "gopkg.in/telebot.v4"
 )
 
-func (m *TelegramBotModule) useOnChatJoinRequest() {
-	m.Bot.Handle(tele.OnChatJoinRequest, func(c tele.Context) error {
-		if c.Chat().ID != m.appConfig.AppConfig().TelegramChannelId() {
+func (m *TelegramBotModule) useOnChatJoinRequest(bot *tele.Bot) {
+	bot.Handle(tele.OnChatJoinRequest, func(c tele.Context) error {
+		// Only channels giving a role make the user wait for access
+		channel := m.accessChannel(c.Chat().ID)
+		if channel == nil || channel.Grant() == models.AccessGrantPremium {
 			return nil
 		}
 
+		ctx := updateContext(c)
 		sender := c.ChatJoinRequest().Sender
-		user, err := m.handleSender(sender)
+		user, err := m.handleSender(ctx, sender)
 
 		// Set join pending status
-		if err != nil {
+		if err == nil {
 			user.SetJoinPending(true)
-			err = m.Ctx.App.Save(user)
+			err = m.Ctx.App.SaveWithContext(ctx, user)
 		}
 
 		if err != nil {
-			m.Logger.Error(
+			core.Logger(ctx, m.Logger).Error(
 				"Failed to handle chat join request",
 				"Error", err,
 				"UserId", sender.ID,
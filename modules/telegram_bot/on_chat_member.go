@@ -1,15 +1,17 @@
 package telegram_bot
 
 import (
+	"github.com/docker-pet/backend/core"
 	tele "gopkg.in/telebot.v4"
 )
 
-func (m *TelegramBotModule) useOnChatMember() {
-	m.Bot.Handle(tele.OnChatMember, func(c tele.Context) error {
+func (m *TelegramBotModule) useOnChatMember(bot *tele.Bot) {
+	bot.Handle(tele.OnChatMember, func(c tele.Context) error {
+		ctx := updateContext(c)
 		member := c.ChatMember().NewChatMember
-		_, err := m.handleChatMember(member, c.Chat().ID)
+		_, err := m.handleChatMember(ctx, member, c.Chat().ID)
 		if err != nil {
-			m.Logger.Error(
+			core.Logger(ctx, m.Logger).Error(
 				"Failed to handle chat member event",
 				"Error", err,
 				"ChatId", c.Chat().ID,
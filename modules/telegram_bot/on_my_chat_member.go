@@ -1,19 +1,21 @@
 package telegram_bot
 
 import (
+	"github.com/docker-pet/backend/core"
 	tele "gopkg.in/telebot.v4"
 )
 
-func (m *TelegramBotModule) useOnMyChatMember() {
-	m.Bot.Handle(tele.OnMyChatMember, func(c tele.Context) error {
+func (m *TelegramBotModule) useOnMyChatMember(bot *tele.Bot) {
+	bot.Handle(tele.OnMyChatMember, func(c tele.Context) error {
 		// No role change, do nothing
 		if c.ChatMember().NewChatMember.Role == c.ChatMember().OldChatMember.Role {
 			return nil
 		}
 
+		logger := core.Logger(updateContext(c), m.Logger)
 		switch c.ChatMember().NewChatMember.Role {
 		case tele.Administrator, tele.Creator:
-			m.Logger.Info(
+			logger.Info(
 				"Telegram bot joined chat as administrator:",
 				"ChatId", c.Chat().ID,
 				"Title", c.Chat().Title,
@@ -21,14 +23,14 @@ func (m *TelegramBotModule) useOnMyChatMember() {
 
 		case tele.Left:
 		case tele.Kicked:
-			m.Logger.Info(
+			logger.Info(
 				"Telegram bot left chat:",
 				"ChatId", c.Chat().ID,
 				"Title", c.Chat().Title,
 			)
 
 		case tele.Restricted:
-			m.Logger.Info(
+			logger.Info(
 				"Telegram bot restricted in chat & left:",
 				"ChatId", c.Chat().ID,
 				"Title", c.Chat().Title,
@@ -36,7 +38,7 @@ func (m *TelegramBotModule) useOnMyChatMember() {
 			return c.Bot().Leave(c.Chat())
 
 		default:
-			m.Logger.Info(
+			logger.Info(
 				"Telegram bot received unknown chat member update:",
 				"ChatId", c.Chat().ID,
 				"Title", c.Chat().Title,
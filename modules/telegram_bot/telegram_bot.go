@@ -1,20 +1,52 @@
 package telegram_bot
 
 import (
+	"context"
+	"errors"
+	"fmt"
 	"log/slog"
+	"slices"
+	"sync"
 	"time"
 
 	"github.com/docker-pet/backend/core"
+	"github.com/docker-pet/backend/models"
 	"github.com/docker-pet/backend/modules/app_config"
+	"github.com/docker-pet/backend/modules/lampa"
+	"github.com/docker-pet/backend/modules/outline"
 	"github.com/docker-pet/backend/modules/users"
 	pbCore "github.com/pocketbase/pocketbase/core"
+	"github.com/pocketbase/pocketbase/tools/cron"
 	tele "gopkg.in/telebot.v4"
 )
 
 type Config struct {
-	CronUserSyncInterval   time.Duration
-	CronUserSyncExpression string
-	CronUsersPerSync       int
+	CronUserSyncInterval   time.Duration `yaml:"cronUserSyncInterval"`
+	CronUserSyncExpression string        `yaml:"cronUserSyncExpression"`
+	CronUsersPerSync       int           `yaml:"cronUsersPerSync"`
+	PollerMode             string        `yaml:"pollerMode"`      // webhook, longpoll or auto
+	LongPollTimeout        time.Duration `yaml:"longPollTimeout"` // getUpdates timeout in long polling mode
+}
+
+func (c *Config) Validate() error {
+	var errs []error
+	if _, err := cron.NewSchedule(c.CronUserSyncExpression); err != nil {
+		errs = append(errs, fmt.Errorf("cronUserSyncExpression: %w", err))
+	}
+	if c.CronUserSyncInterval <= 0 {
+		errs = append(errs, errors.New("cronUserSyncInterval must be positive"))
+	}
+	if c.CronUsersPerSync < 1 {
+		errs = append(errs, errors.New("cronUsersPerSync must be at least 1"))
+	}
+	if !slices.Contains([]string{PollerWebhook, PollerLongPoll, PollerAuto}, c.PollerMode) {
+		errs = append(errs, fmt.Errorf("pollerMode must be %s, %s or %s", PollerWebhook, PollerLongPoll, PollerAuto))
+	}
+	// The HTTP client of the bot times out after a minute
+	if c.LongPollTimeout <= 0 || c.LongPollTimeout >= time.Minute {
+		errs = append(errs, errors.New("longPollTimeout must be positive and below 1m"))
+	}
+	return errors.Join(errs...)
 }
 
 type TelegramBotModule struct {
@@ -24,25 +56,57 @@ type TelegramBotModule struct {
 
 	appConfig *app_config.AppConfigModule
 	users     *users.UsersModule
+	outline   *outline.OutlineModule // Optional, used by the admin commands
+	lampa     *lampa.LampaModule     // Optional, used by the admin commands
+
+	Bot       *tele.Bot
+	initError error // Last bot initialization error, reported by HealthCheck
+	metrics   *botMetrics
+
+	mu              sync.RWMutex   // Guards Bot, poller, botToken, mode, previousWebhook, ownWebhookUrl and initError
+	poller          *webhookPoller // Receives updates of the current bot, nil when long polling
+	botToken        string         // Token of the current bot, checked by the webhook endpoint
+	mode            string         // Poller mode of the current bot
+	previousWebhook *tele.Webhook  // Webhook deleted by the long polling bot, restored on stop
+	ownWebhookUrl   string         // Webhook last set by this module, it may point to an old domain
+	reloadMu        sync.Mutex     // Serializes bot reloads
 
-	Bot *tele.Bot
+	channels   []*models.AccessChannel // Enabled access channels, highest priority first
+	channelsMu sync.RWMutex            // Guards channels
 }
 
 func (m *TelegramBotModule) Name() string                  { return "telegram_bot" }
 func (m *TelegramBotModule) Deps() []string                { return []string{"users", "app_config"} }
+func (m *TelegramBotModule) OptionalDeps() []string        { return []string{"outline", "lampa"} }
 func (m *TelegramBotModule) SetLogger(logger *slog.Logger) { m.Logger = logger }
 func (m *TelegramBotModule) Init(ctx *core.AppContext, logger *slog.Logger, cfg any) error {
 	m.Ctx = ctx
 	m.Config = cfg.(*Config)
 	m.Logger = logger
-	m.appConfig = m.Ctx.Modules["app_config"].(*app_config.AppConfigModule)
-	m.users = m.Ctx.Modules["users"].(*users.UsersModule)
+	if err := errors.Join(
+		core.Require(ctx, &m.appConfig),
+		core.Require(ctx, &m.users),
+	); err != nil {
+		return err
+	}
+	core.Lookup(ctx, &m.outline)
+	core.Lookup(ctx, &m.lampa)
 
+	m.registerMetrics()
 	m.useUsersRevalidateCron()
+	m.notifySubscriptions()
+	m.watchAccessChannels()
+	m.watchConfigChanges()
 
 	m.Ctx.App.OnServe().BindFunc(func(e *pbCore.ServeEvent) error {
+		m.registerWebhookEndpoint(e)
+
+		if err := m.loadAccessChannels(); err != nil {
+			m.Logger.Error("Failed to load access channels", "Error", err)
+		}
+
 		// Initialize bot
-		bot, err := m.newBot(e)
+		bot, err := m.setupBot()
 		if err != nil {
 			m.Logger.Warn(
 				"Failed to initialize Telegram bot",
@@ -51,22 +115,11 @@ func (m *TelegramBotModule) Init(ctx *core.AppContext, logger *slog.Logger, cfg
 			)
 			return e.Next()
 		}
-		m.Bot = bot
-
-		// Handlers
-		m.useAccessMiddleware()
-		m.useOnChatMember()
-		m.useOnChatJoinRequest()
-		m.useOnMyChatMember()
-		m.useStartCommand()
-		m.appConfig.SetBotUsername(m.Bot.Me.Username)
-
-		// Start
-		go m.Bot.Start()
+
 		m.Logger.Info(
 			"Telegram Bot module initialized",
 			"Config", m.Config,
-			"BotUsername", m.Bot.Me.Username,
+			"BotUsername", bot.Me.Username,
 		)
 
 		return e.Next()
@@ -74,3 +127,17 @@ func (m *TelegramBotModule) Init(ctx *core.AppContext, logger *slog.Logger, cfg
 
 	return nil
 }
+
+func (m *TelegramBotModule) Start(ctx context.Context) error {
+	if bot := m.currentBot(); bot != nil {
+		go bot.Start()
+	}
+	return nil
+}
+
+func (m *TelegramBotModule) Stop(ctx context.Context) error {
+	m.Ctx.App.Cron().Remove(cronUsersRevalidateJobId)
+
+	_, _, err := m.stopBot(ctx)
+	return err
+}
@@ -0,0 +1,290 @@
+package telegram_bot
+
+import (
+	"bytes"
+	"fmt"
+	"html"
+	"slices"
+	"time"
+
+	"github.com/docker-pet/backend/core"
+	"github.com/docker-pet/backend/i18n"
+	"github.com/docker-pet/backend/models"
+	"github.com/skip2/go-qrcode"
+	tele "gopkg.in/telebot.v4"
+)
+
+// Callback buttons of the user commands
+var (
+	serverButton = &tele.Btn{Unique: "outline_server"} // Data is the server id, empty for autopick
+	resetButton  = &tele.Btn{Unique: "outline_reset"}
+)
+
+const qrCodeSize = 512
+
+// userHandler handles a command or a button of a user in a private chat.
+type userHandler func(c tele.Context, user *models.User, locale string) error
+
+// useUserCommands registers the self-service commands, they give users the
+// same things the Mini App does.
+func (m *TelegramBotModule) useUserCommands(bot *tele.Bot) {
+	bot.Handle("/vpn", m.userCommand(m.handleVpnCommand))
+	bot.Handle("/lampa", m.userCommand(m.handleLampaCommand))
+	bot.Handle("/status", m.userCommand(m.handleStatusCommand))
+	bot.Handle("/servers", m.userCommand(m.handleServersCommand))
+	bot.Handle("/reset", m.userCommand(m.handleResetCommand))
+	bot.Handle(serverButton, m.userCommand(m.handleServerButton))
+	bot.Handle(resetButton, m.userCommand(m.handleResetButton))
+}
+
+func (m *TelegramBotModule) userCommand(handler userHandler) tele.HandlerFunc {
+	return func(c tele.Context) error {
+		if c.Chat() == nil || c.Chat().Type != tele.ChatPrivate || c.Sender() == nil {
+			return nil
+		}
+
+		locale := senderLocale(c)
+		user, err := m.handleSender(updateContext(c), c.Sender())
+		if err != nil {
+			core.Logger(updateContext(c), m.Logger).Error("Failed to handle command sender", "Command", c.Text(), "Error", err)
+			return m.reply(c, locale, "telegram_bot.user.failed", nil)
+		}
+
+		return handler(c, user, locale)
+	}
+}
+
+// requireActive answers guests, ok is false when the user can't use the
+// command.
+func (m *TelegramBotModule) requireActive(c tele.Context, user *models.User, locale string) (ok bool, err error) {
+	if user.IsActive() {
+		return true, nil
+	}
+	if c.Callback() != nil {
+		return false, c.Respond(&tele.CallbackResponse{Text: m.Ctx.I18n.T(locale, "telegram_bot.user.guest_forbidden")})
+	}
+	return false, m.reply(c, locale, "telegram_bot.user.guest_forbidden", nil)
+}
+
+func (m *TelegramBotModule) handleVpnCommand(c tele.Context, user *models.User, locale string) error {
+	if m.outline == nil {
+		return m.reply(c, locale, "telegram_bot.user.outline_disabled", nil)
+	}
+	if ok, err := m.requireActive(c, user, locale); !ok {
+		return err
+	}
+
+	accessKey := m.outline.AccessKeyUrl(user.Id, user.OutlineToken())
+	png, err := qrcode.Encode(accessKey, qrcode.Medium, qrCodeSize)
+	if err != nil {
+		core.Logger(updateContext(c), m.Logger).Error("Failed to encode access key QR code", "UserId", user.Id, "Error", err)
+		return m.reply(c, locale, "telegram_bot.user.failed", nil)
+	}
+
+	// Telegram buttons can't open ssconf:// links, the redirect endpoint does
+	markup := &tele.ReplyMarkup{}
+	markup.Inline(markup.Row(markup.URL(
+		m.Ctx.I18n.T(locale, "telegram_bot.user.vpn.button"),
+		fmt.Sprintf("https://%s/api/outline/redirect/%s/%s", m.appConfig.AppConfig().AppDomain(), user.Id, user.OutlineToken()),
+	)))
+
+	return c.Send(&tele.Photo{
+		File:    tele.FromReader(bytes.NewReader(png)),
+		Caption: m.Ctx.I18n.T(locale, "telegram_bot.user.vpn", i18n.Args{"accessKey": html.EscapeString(accessKey)}),
+	}, &tele.SendOptions{
+		ParseMode:   tele.ModeHTML,
+		ReplyMarkup: markup,
+	})
+}
+
+func (m *TelegramBotModule) handleLampaCommand(c tele.Context, user *models.User, locale string) error {
+	if m.lampa == nil {
+		return m.reply(c, locale, "telegram_bot.user.lampa_disabled", nil)
+	}
+	if ok, err := m.requireActive(c, user, locale); !ok {
+		return err
+	}
+
+	// The Lampa user is created in the background when the user is activated
+	lampaUser, err := m.lampa.GetLampaUserByUserId(user.Id)
+	if err != nil || lampaUser.Disabled() {
+		return m.reply(c, locale, "telegram_bot.user.lampa_unavailable", nil)
+	}
+
+	markup := &tele.ReplyMarkup{}
+	markup.Inline(markup.Row(markup.URL(m.Ctx.I18n.T(locale, "telegram_bot.user.lampa.button"), m.lampa.PublicUrl())))
+
+	return c.Send(m.Ctx.I18n.T(locale, "telegram_bot.user.lampa", i18n.Args{
+		"url":     html.EscapeString(m.lampa.PublicUrl()),
+		"authKey": html.EscapeString(lampaUser.AuthKey()),
+	}), &tele.SendOptions{
+		ParseMode:             tele.ModeHTML,
+		DisableWebPagePreview: true,
+		ReplyMarkup:           markup,
+	})
+}
+
+func (m *TelegramBotModule) handleStatusCommand(c tele.Context, user *models.User, locale string) error {
+	now := time.Now()
+	expires := m.Ctx.I18n.T(locale, "telegram_bot.user.status.no_expiry")
+	if date := user.AccessExpires(now); !date.IsZero() {
+		expires = formatDateTime(date.Time())
+	}
+
+	// The stored flag is only recomputed on save, a period may have ended
+	premium := m.Ctx.I18n.T(locale, "telegram_bot.no")
+	if user.EffectivePremium(now) {
+		premium = m.Ctx.I18n.T(locale, "telegram_bot.yes")
+	}
+
+	server := m.Ctx.I18n.T(locale, "telegram_bot.user.server.auto")
+	if m.outline != nil && user.OutlineServer() != "" {
+		if outlineServer, err := m.outline.GetServerById(user.OutlineServer()); err == nil {
+			server = html.EscapeString(serverLabel(outlineServer))
+		}
+	}
+
+	key := "telegram_bot.user.status"
+	if !user.IsActive() {
+		key = "telegram_bot.user.status.guest"
+	}
+
+	return m.reply(c, locale, key, i18n.Args{
+		"user":    userLabel(user),
+		"role":    m.Ctx.I18n.T(locale, "telegram_bot.user.role."+string(user.Role())),
+		"expires": expires,
+		"premium": premium,
+		"server":  server,
+	})
+}
+
+func (m *TelegramBotModule) handleServersCommand(c tele.Context, user *models.User, locale string) error {
+	if m.outline == nil {
+		return m.reply(c, locale, "telegram_bot.user.outline_disabled", nil)
+	}
+	if ok, err := m.requireActive(c, user, locale); !ok {
+		return err
+	}
+
+	markup, err := m.serversMarkup(user, locale)
+	if err != nil {
+		core.Logger(updateContext(c), m.Logger).Error("Failed to get available Outline servers", "UserId", user.Id, "Error", err)
+		return m.reply(c, locale, "telegram_bot.user.failed", nil)
+	}
+	if markup == nil {
+		return m.reply(c, locale, "telegram_bot.user.servers.empty", nil)
+	}
+
+	return c.Send(m.Ctx.I18n.T(locale, "telegram_bot.user.servers"), &tele.SendOptions{ReplyMarkup: markup})
+}
+
+// serversMarkup has a button per available server and one for autopick,
+// the selected one is checked. It is nil when no server is available.
+func (m *TelegramBotModule) serversMarkup(user *models.User, locale string) (*tele.ReplyMarkup, error) {
+	servers, err := m.outline.AvailableServers(user)
+	if err != nil || len(servers) == 0 {
+		return nil, err
+	}
+
+	markup := &tele.ReplyMarkup{}
+	button := func(text string, serverId string) tele.Row {
+		if user.OutlineServer() == serverId {
+			text = "✅ " + text
+		}
+		return markup.Row(markup.Data(text, serverButton.Unique, serverId))
+	}
+
+	rows := []tele.Row{button(m.Ctx.I18n.T(locale, "telegram_bot.user.server.auto"), "")}
+	for _, server := range servers {
+		rows = append(rows, button(serverLabel(server), server.Id))
+	}
+	markup.Inline(rows...)
+
+	return markup, nil
+}
+
+// handleServerButton selects the server like the settings endpoint of the
+// outline module, only servers available to the user can be picked.
+func (m *TelegramBotModule) handleServerButton(c tele.Context, user *models.User, locale string) error {
+	if m.outline == nil {
+		return c.Respond(&tele.CallbackResponse{Text: m.Ctx.I18n.T(locale, "telegram_bot.user.outline_disabled")})
+	}
+	if ok, err := m.requireActive(c, user, locale); !ok {
+		return err
+	}
+
+	serverId := c.Callback().Data
+	if serverId != "" {
+		servers, err := m.outline.AvailableServers(user)
+		if err != nil {
+			core.Logger(updateContext(c), m.Logger).Error("Failed to get available Outline servers", "UserId", user.Id, "Error", err)
+			return c.Respond(&tele.CallbackResponse{Text: m.Ctx.I18n.T(locale, "telegram_bot.user.failed")})
+		}
+		if !slices.ContainsFunc(servers, func(server *models.OutlineServer) bool { return server.Id == serverId }) {
+			return c.Respond(&tele.CallbackResponse{Text: m.Ctx.I18n.T(locale, "telegram_bot.user.server.unavailable")})
+		}
+	}
+
+	before := user.OutlineServer()
+	if before != serverId {
+		user.SetOutlineServer(serverId)
+		if err := m.Ctx.App.SaveWithContext(updateContext(c), user); err != nil {
+			core.Logger(updateContext(c), m.Logger).Error("Failed to save user", "UserId", user.Id, "Error", err)
+			return c.Respond(&tele.CallbackResponse{Text: m.Ctx.I18n.T(locale, "telegram_bot.user.failed")})
+		}
+
+		m.auditCommand(user, core.AuditEntry{
+			Action:   core.AuditOutlineSettingsChanged,
+			TargetId: user.Id,
+			Before:   map[string]any{"outlineServer": before},
+			After:    map[string]any{"outlineServer": serverId},
+		})
+	}
+
+	if markup, err := m.serversMarkup(user, locale); err == nil && markup != nil {
+		c.Edit(m.Ctx.I18n.T(locale, "telegram_bot.user.servers"), &tele.SendOptions{ReplyMarkup: markup})
+	}
+	return c.Respond(&tele.CallbackResponse{Text: m.Ctx.I18n.T(locale, "telegram_bot.user.server.selected")})
+}
+
+// handleResetCommand asks to confirm, the old access keys stop working.
+func (m *TelegramBotModule) handleResetCommand(c tele.Context, user *models.User, locale string) error {
+	if m.outline == nil {
+		return m.reply(c, locale, "telegram_bot.user.outline_disabled", nil)
+	}
+	if ok, err := m.requireActive(c, user, locale); !ok {
+		return err
+	}
+
+	markup := &tele.ReplyMarkup{}
+	markup.Inline(markup.Row(markup.Data(m.Ctx.I18n.T(locale, "telegram_bot.user.reset.button"), resetButton.Unique)))
+
+	return c.Send(m.Ctx.I18n.T(locale, "telegram_bot.user.reset.confirm"), &tele.SendOptions{ReplyMarkup: markup})
+}
+
+func (m *TelegramBotModule) handleResetButton(c tele.Context, user *models.User, locale string) error {
+	if ok, err := m.requireActive(c, user, locale); !ok {
+		return err
+	}
+
+	// Saving publishes OutlineTokenRotated, which reconfigures the servers
+	user.GenerateOutlineToken()
+	if err := m.Ctx.App.SaveWithContext(updateContext(c), user); err != nil {
+		core.Logger(updateContext(c), m.Logger).Error("Failed to save user", "UserId", user.Id, "Error", err)
+		return c.Respond(&tele.CallbackResponse{Text: m.Ctx.I18n.T(locale, "telegram_bot.user.failed")})
+	}
+
+	// Never store the token itself
+	m.auditCommand(user, core.AuditEntry{
+		Action:   core.AuditOutlineTokenRotated,
+		TargetId: user.Id,
+	})
+
+	c.Edit(m.Ctx.I18n.T(locale, "telegram_bot.user.reset.done"))
+	return c.Respond()
+}
+
+// serverLabel is the flag and the slug of the server.
+func serverLabel(server *models.OutlineServer) string {
+	return fmt.Sprintf("%s %s", server.Country().Emoji(), server.Slug())
+}
@@ -1,14 +1,23 @@
 package telegram_bot
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"io"
 
+	"github.com/docker-pet/backend/core"
 	"github.com/docker-pet/backend/models"
+	"github.com/docker-pet/backend/modules/users"
 	"github.com/pocketbase/pocketbase/tools/types"
 	tele "gopkg.in/telebot.v4"
 )
 
-func (m *TelegramBotModule) handleChatMember(member *tele.ChatMember, channelId int64) (*models.User, error) {
+func (m *TelegramBotModule) handleChatMember(ctx context.Context, member *tele.ChatMember, channelId int64) (*models.User, error) {
+	if m.accessChannel(channelId) == nil {
+		return nil, fmt.Errorf("chat %d is not an access channel", channelId)
+	}
+
 	user, err := m.users.GetUserByTelegramId(member.User.ID)
 	needToSave := false
 	if err != nil {
@@ -22,70 +31,37 @@ func (m *TelegramBotModule) handleChatMember(member *tele.ChatMember, channelId
 	// Sync data
 	user.SetSynced(types.NowDateTime())
 
-	// Role detection
-	if channelId == m.appConfig.AppConfig().TelegramChannelId() {
-		role := models.RoleGuest
-		switch member.Role {
-		case tele.Creator, tele.Administrator:
-			role = models.RoleAdmin
-		case tele.Member:
-			role = models.RoleUser
-		default:
-			role = models.RoleGuest
-		}
-
-		if user.Role() != role {
-			user.SetRole(role)
-			needToSave = true
-		}
-	}
-
-	// Premium detection
-	if channelId == m.appConfig.AppConfig().TelegramPremiumChannelId() {
-		premium := false
-		switch member.Role {
-		case tele.Administrator, tele.Creator, tele.Member:
-			premium = true
-		default:
-			premium = false
-		}
-
-		if user.Premium() != premium {
-			user.SetPremium(premium)
-			needToSave = true
-		}
-	}
-
-	// Username
-	if user.TelegramUsername() != member.User.Username {
-		user.SetTelegramUsername(member.User.Username)
-		needToSave = true
-	}
-
-	// Name
-	oldName := user.Name()
-	user.SetName(member.User.FirstName, member.User.LastName)
-	if user.Name() != oldName {
+	// The membership role and premium are computed across all access
+	// channels, the effective ones also depend on the admin overrides and
+	// are computed when the user is saved
+	user.SetMembership(channelId, memberStatus(member))
+	audits := m.applyMemberships(user, channelId)
+	if len(audits) > 0 {
 		needToSave = true
 	}
 
-	// Language code
-	if user.Language() != member.User.LanguageCode {
-		user.SetLanguage(member.User.LanguageCode)
+	if syncProfile(user, member.User) {
 		needToSave = true
 	}
 
 	// Need to save user?
 	if needToSave {
-		if err := m.Ctx.App.Save(user); err != nil {
+		if err := m.Ctx.App.SaveWithContext(ctx, user); err != nil {
 			return nil, fmt.Errorf("failed to save user: %w", err)
 		}
 	}
 
+	for _, entry := range audits {
+		entry.Module = m.Name()
+		entry.ActorLabel = core.AuditActorTelegram
+		entry.TargetId = user.Id
+		m.Ctx.Audit(entry)
+	}
+
 	return user, nil
 }
 
-func (m *TelegramBotModule) handleSender(sender *tele.User) (*models.User, error) {
+func (m *TelegramBotModule) handleSender(ctx context.Context, sender *tele.User) (*models.User, error) {
 	user, err := m.users.GetUserByTelegramId(sender.ID)
 	needToSave := false
 	if err != nil {
@@ -96,32 +72,69 @@ func (m *TelegramBotModule) handleSender(sender *tele.User) (*models.User, error
 		needToSave = true
 	}
 
+	if syncProfile(user, sender) {
+		needToSave = true
+	}
+
+	// Need to save user?
+	if needToSave {
+		if err := m.Ctx.App.SaveWithContext(ctx, user); err != nil {
+			return nil, fmt.Errorf("failed to save user: %w", err)
+		}
+	}
+
+	// Users who only talk to the bot get the profile photo, the Mini App
+	// keeps the avatar of the others up to date
+	if user.AvatarHash() == "" {
+		m.users.QueueAvatar(user.Id, m.ProfilePhotoSource(sender))
+	}
+
+	return user, nil
+}
+
+// syncProfile copies the Telegram username, name and language to the user,
+// returns whether anything changed.
+func syncProfile(user *models.User, tgUser *tele.User) bool {
+	changed := false
 
 	// Username
-	if user.TelegramUsername() != sender.Username {
-		user.SetTelegramUsername(sender.Username)
-		needToSave = true
+	if user.TelegramUsername() != tgUser.Username {
+		user.SetTelegramUsername(tgUser.Username)
+		changed = true
 	}
 
 	// Name
 	oldName := user.Name()
-	user.SetName(sender.FirstName, sender.LastName)
+	user.SetName(tgUser.FirstName, tgUser.LastName)
 	if user.Name() != oldName {
-		needToSave = true
+		changed = true
 	}
 
 	// Language code
-	if user.Language() != sender.LanguageCode {
-		user.SetLanguage(sender.LanguageCode)
-		needToSave = true
+	if user.Language() != tgUser.LanguageCode {
+		user.SetLanguage(tgUser.LanguageCode)
+		changed = true
 	}
 
-	// Need to save user?
-	if needToSave {
-		if err := m.Ctx.App.Save(user); err != nil {
-			return nil, fmt.Errorf("failed to save user: %w", err)
+	return changed
+}
+
+// ProfilePhotoSource opens the largest size of the current profile photo.
+func (m *TelegramBotModule) ProfilePhotoSource(sender *tele.User) users.AvatarSource {
+	return func(ctx context.Context) (io.ReadCloser, error) {
+		bot := m.currentBot()
+		if bot == nil {
+			return nil, errors.New("telegram bot is not initialized")
 		}
-	}
 
-	return user, nil
+		photos, err := bot.ProfilePhotosOf(sender)
+		if err != nil {
+			return nil, err
+		}
+		if len(photos) == 0 {
+			return nil, users.ErrNoAvatar
+		}
+
+		return bot.File(&photos[0].File)
+	}
 }
@@ -0,0 +1,59 @@
+package telegram_bot
+
+import (
+	"encoding/json"
+	"net/http"
+	"sync"
+
+	tele "gopkg.in/telebot.v4"
+)
+
+// webhookPoller hands updates received by the HTTP endpoint over to the bot.
+// tele.Webhook without its own listener closes the stop channel twice, so
+// Bot.Stop() panics; this poller only waits for the stop signal.
+type webhookPoller struct {
+	onUpdate func(update *tele.Update) // Called for every decoded update
+
+	mu   sync.RWMutex
+	dest chan tele.Update
+	stop chan struct{}
+}
+
+func (p *webhookPoller) Poll(b *tele.Bot, dest chan tele.Update, stop chan struct{}) {
+	p.mu.Lock()
+	p.dest = dest
+	p.stop = stop
+	p.mu.Unlock()
+
+	<-stop
+
+	p.mu.Lock()
+	p.dest = nil
+	p.stop = nil
+	p.mu.Unlock()
+}
+
+func (p *webhookPoller) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	var update tele.Update
+	if err := json.NewDecoder(r.Body).Decode(&update); err != nil {
+		return
+	}
+
+	if p.onUpdate != nil {
+		p.onUpdate(&update)
+	}
+
+	p.mu.RLock()
+	dest, stop := p.dest, p.stop
+	p.mu.RUnlock()
+
+	if dest == nil {
+		return
+	}
+
+	select {
+	case dest <- update:
+	case <-stop:
+	case <-r.Context().Done():
+	}
+}
@@ -1,10 +1,12 @@
 package telegram_miniapp
 
 import (
+	"github.com/docker-pet/backend/modules/users"
 	"github.com/pocketbase/pocketbase/apis"
 	"github.com/pocketbase/pocketbase/core"
 	"github.com/pocketbase/pocketbase/tools/types"
 	initdata "github.com/telegram-mini-apps/init-data-golang"
+	tele "gopkg.in/telebot.v4"
 )
 
 func (m *TelegramMiniappModule) registerAuthVerifyEndpoint() {
@@ -15,18 +17,18 @@ func (m *TelegramMiniappModule) registerAuthVerifyEndpoint() {
 				InitData string `json:"initData" form:"initData"`
 			}{}
 			if err := e.BindBody(&data); err != nil {
-				return e.BadRequestError("Failed to read request data", err)
+				return e.BadRequestError(m.Ctx.T(e, "telegram_miniapp.request_invalid"), err)
 			}
 
 			// Will return error in case, init data is invalid.
 			if err := initdata.Validate(data.InitData, m.appConfig.AppConfig().TelegramBotToken(), m.Config.AuthTokenLifetime); err != nil {
-				return e.BadRequestError("Invalid init data", err)
+				return e.BadRequestError(m.Ctx.T(e, "telegram_miniapp.init_data_invalid"), err)
 			}
 
 			// Parse init data
 			tgUser, err := initdata.Parse(data.InitData)
 			if err != nil {
-				return e.BadRequestError("Failed to parse data", err)
+				return e.BadRequestError(m.Ctx.T(e, "telegram_miniapp.init_data_parse_failed"), err)
 			}
 
 			// Get user by Telegram ID
@@ -35,7 +37,7 @@ func (m *TelegramMiniappModule) registerAuthVerifyEndpoint() {
 			if err != nil {
 				newUser, err := m.users.NewUser(tgUser.User.ID)
 				if err != nil {
-					return e.InternalServerError("Failed to create new user", err)
+					return e.InternalServerError(m.Ctx.I18n.T(tgUser.User.LanguageCode, "telegram_miniapp.user_create_failed"), err)
 				}
 				user = newUser
 				user.SetSynced(types.NowDateTime().AddDate(-20, 0, 0))
@@ -61,19 +63,24 @@ func (m *TelegramMiniappModule) registerAuthVerifyEndpoint() {
 				needToSave = true
 			}
 
-			// New avatar
-			avatarChanged := m.users.UploadAvatar(user, tgUser.User.PhotoURL)
-			if avatarChanged {
-				needToSave = true
-			}
-
 			// Save user if needed
 			if needToSave {
-				if err := m.Ctx.App.Save(user); err != nil {
-					return e.InternalServerError("Failed to save user", err)
+				if err := m.Ctx.App.SaveWithContext(e.Request.Context(), user); err != nil {
+					return e.InternalServerError(m.Ctx.I18n.T(user.Language(), "telegram_miniapp.user_save_failed"), err)
 				}
 			}
 
+			// Avatar is refreshed in the background. The photo URL is often
+			// an SVG, so the bot profile photo is preferred.
+			var sources []users.AvatarSource
+			if m.telegramBot != nil {
+				sources = append(sources, m.telegramBot.ProfilePhotoSource(&tele.User{ID: tgUser.User.ID}))
+			}
+			if tgUser.User.PhotoURL != "" {
+				sources = append(sources, m.users.AvatarFromUrl(tgUser.User.PhotoURL))
+			}
+			m.users.QueueAvatar(user.Id, users.FirstAvatar(sources...))
+
 			return apis.RecordAuthResponse(e, user.Record, "", tgUser)
 		})
 
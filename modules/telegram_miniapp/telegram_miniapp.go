@@ -1,16 +1,25 @@
 package telegram_miniapp
 
 import (
+	"errors"
 	"log/slog"
 	"time"
 
 	"github.com/docker-pet/backend/core"
 	"github.com/docker-pet/backend/modules/app_config"
+	"github.com/docker-pet/backend/modules/telegram_bot"
 	"github.com/docker-pet/backend/modules/users"
 )
 
 type Config struct {
-	AuthTokenLifetime time.Duration
+	AuthTokenLifetime time.Duration `yaml:"authTokenLifetime"`
+}
+
+func (c *Config) Validate() error {
+	if c.AuthTokenLifetime <= 0 {
+		return errors.New("authTokenLifetime must be positive")
+	}
+	return nil
 }
 
 type TelegramMiniappModule struct {
@@ -18,19 +27,26 @@ type TelegramMiniappModule struct {
 	Config *Config
 	Logger *slog.Logger
 
-	users     *users.UsersModule
-	appConfig *app_config.AppConfigModule
+	users       *users.UsersModule
+	appConfig   *app_config.AppConfigModule
+	telegramBot *telegram_bot.TelegramBotModule
 }
 
 func (m *TelegramMiniappModule) Name() string                  { return "telegram_miniapp" }
 func (m *TelegramMiniappModule) Deps() []string                { return []string{"users", "app_config"} }
+func (m *TelegramMiniappModule) OptionalDeps() []string        { return []string{"telegram_bot"} }
 func (m *TelegramMiniappModule) SetLogger(logger *slog.Logger) { m.Logger = logger }
 func (m *TelegramMiniappModule) Init(ctx *core.AppContext, logger *slog.Logger, cfg any) error {
 	m.Ctx = ctx
 	m.Config = cfg.(*Config)
 	m.Logger = logger
-	m.users = m.Ctx.Modules["users"].(*users.UsersModule)
-	m.appConfig = m.Ctx.Modules["app_config"].(*app_config.AppConfigModule)
+	if err := errors.Join(
+		core.Require(ctx, &m.users),
+		core.Require(ctx, &m.appConfig),
+	); err != nil {
+		return err
+	}
+	core.Lookup(ctx, &m.telegramBot)
 
 	m.registerAuthVerifyEndpoint()
 
@@ -0,0 +1,80 @@
+package translations
+
+import (
+	"context"
+	"log/slog"
+
+	"github.com/docker-pet/backend/core"
+	"github.com/docker-pet/backend/i18n"
+	"github.com/docker-pet/backend/models"
+	pbCore "github.com/pocketbase/pocketbase/core"
+)
+
+type Config struct{}
+
+// TranslationsModule loads the admin overrides of the translations
+// collection into the catalog and reloads them when they change.
+type TranslationsModule struct {
+	Ctx    *core.AppContext
+	Config *Config
+	Logger *slog.Logger
+}
+
+func (m *TranslationsModule) Name() string                  { return "translations" }
+func (m *TranslationsModule) Deps() []string                { return nil }
+func (m *TranslationsModule) SetLogger(logger *slog.Logger) { m.Logger = logger }
+func (m *TranslationsModule) Init(ctx *core.AppContext, logger *slog.Logger, cfg any) error {
+	m.Ctx = ctx
+	m.Config = cfg.(*Config)
+	m.Logger = logger
+
+	m.watchChanges()
+
+	m.Logger.Info("Translations module initialized", "Locales", m.Ctx.I18n.Locales())
+	return nil
+}
+
+func (m *TranslationsModule) Start(ctx context.Context) error {
+	return m.loadOverrides()
+}
+
+func (m *TranslationsModule) watchChanges() {
+	reload := func(e *pbCore.RecordEvent) error {
+		if err := m.loadOverrides(); err != nil {
+			core.Logger(e.Context, m.Logger).Error("Failed to reload translation overrides", "Err", err)
+		}
+		return e.Next()
+	}
+
+	m.Ctx.App.OnRecordAfterCreateSuccess("translations").BindFunc(reload)
+	m.Ctx.App.OnRecordAfterUpdateSuccess("translations").BindFunc(reload)
+	m.Ctx.App.OnRecordAfterDeleteSuccess("translations").BindFunc(reload)
+}
+
+func (m *TranslationsModule) loadOverrides() error {
+	records, err := m.Ctx.App.FindAllRecords("translations")
+	if err != nil {
+		return err
+	}
+
+	overrides := map[string]map[string]i18n.Message{}
+	for _, record := range records {
+		translation := &models.Translation{}
+		translation.SetProxyRecord(record)
+
+		message := i18n.Message{}
+		for form, text := range translation.Plurals() {
+			message[form] = text
+		}
+		message[i18n.PluralOther] = translation.Value()
+
+		if overrides[translation.Locale()] == nil {
+			overrides[translation.Locale()] = map[string]i18n.Message{}
+		}
+		overrides[translation.Locale()][translation.Key()] = message
+	}
+
+	m.Ctx.I18n.SetOverrides(overrides)
+	m.Logger.Debug("Translation overrides loaded", "Count", len(records))
+	return nil
+}
@@ -0,0 +1,66 @@
+package users
+
+import (
+	"context"
+	"time"
+
+	"github.com/docker-pet/backend/core"
+	"github.com/pocketbase/dbx"
+	pbCore "github.com/pocketbase/pocketbase/core"
+	"github.com/pocketbase/pocketbase/tools/types"
+)
+
+const cronPremiumExpiryJobId = "users_premium_expiry"
+
+// bindAccessHooks stores the effective role and premium flag on every
+// save, whoever changed the membership or the admin overrides.
+func (m *UsersModule) bindAccessHooks() {
+	applyAccess := func(e *pbCore.RecordEvent) error {
+		ProxyUser(e.Record).ApplyAccess(time.Now())
+		return e.Next()
+	}
+
+	m.Ctx.App.OnRecordCreate("users").BindFunc(applyAccess)
+	m.Ctx.App.OnRecordUpdate("users").BindFunc(applyAccess)
+}
+
+// usePremiumExpiryCron clears the premium grants that have expired, so the
+// effective premium flag is recomputed and PremiumChanged is published. A
+// cleared grant is not selected again, even when a premium period or the
+// membership keeps the user premium.
+func (m *UsersModule) usePremiumExpiryCron() {
+	m.Ctx.App.Cron().MustAdd(cronPremiumExpiryJobId, m.Config.CronPremiumExpiryExpression, func() {
+		ctx := core.WithCorrelationId(context.Background(), core.NewCorrelationId("cron"))
+		logger := core.Logger(ctx, m.Logger)
+		now := types.NowDateTime()
+
+		users, err := m.GetAllUsers(dbx.NewExp(
+			"premiumUntil != '' AND premiumUntil <= {:now}",
+			dbx.Params{"now": now.String()},
+		))
+		if err != nil {
+			logger.Warn("Failed to find users with expired premium grants", "Err", err)
+			return
+		}
+
+		for _, user := range users {
+			expired := user.PremiumUntil()
+			if !user.ExpirePremiumGrant(now.Time()) {
+				continue
+			}
+			if err := m.Ctx.App.SaveWithContext(ctx, user); err != nil {
+				logger.Error("Failed to revoke expired premium grant", "UserId", user.Id, "Error", err)
+				continue
+			}
+
+			m.Ctx.Audit(core.AuditEntry{
+				Module:     m.Name(),
+				Action:     core.AuditUserPremiumExpired,
+				ActorLabel: core.AuditActorSystem,
+				TargetId:   user.Id,
+				Before:     map[string]any{"premiumUntil": expired},
+			})
+			logger.Info("Premium grant expired", "UserId", user.Id)
+		}
+	})
+}
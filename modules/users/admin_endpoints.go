@@ -0,0 +1,155 @@
+package users
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/docker-pet/backend/core"
+	"github.com/docker-pet/backend/models"
+	pbCore "github.com/pocketbase/pocketbase/core"
+	"github.com/pocketbase/pocketbase/tools/types"
+)
+
+type accessResponse struct {
+	UserId        string          `json:"userId"`
+	Role          models.UserRole `json:"role"`
+	Premium       bool            `json:"premium"`
+	MemberRole    models.UserRole `json:"memberRole"`
+	MemberPremium bool            `json:"memberPremium"`
+	Banned        bool            `json:"banned"`
+	BanReason     string          `json:"banReason"`
+	RoleOverride  models.UserRole `json:"roleOverride"`
+	PremiumUntil  types.DateTime  `json:"premiumUntil"`
+}
+
+// registerAdminEndpoints adds the admin overrides of the access derived
+// from the Telegram channels. Saving recomputes role and premium.
+func (m *UsersModule) registerAdminEndpoints() {
+	m.Ctx.App.OnServe().BindFunc(func(se *pbCore.ServeEvent) error {
+		group := se.Router.Group("/api/admin/users/{id}")
+		group.Bind(core.RequireAdmin())
+
+		group.GET("/access", func(e *pbCore.RequestEvent) error {
+			user, err := m.GetUserById(e.Request.PathValue("id"))
+			if err != nil {
+				return e.NotFoundError(m.Ctx.T(e, "users.user_not_found"), err)
+			}
+			return e.JSON(http.StatusOK, toAccessResponse(user))
+		})
+
+		group.POST("/ban", func(e *pbCore.RequestEvent) error {
+			var body struct {
+				Reason string `json:"reason"`
+			}
+			return m.updateAccess(e, &body, func(user *models.User) (string, any, any) {
+				before := map[string]any{"banned": user.Banned(), "banReason": user.BanReason()}
+				user.SetBanned(true, body.Reason)
+				return core.AuditUserBanned, before, map[string]any{"banned": true, "banReason": body.Reason}
+			})
+		})
+
+		group.DELETE("/ban", func(e *pbCore.RequestEvent) error {
+			return m.updateAccess(e, nil, func(user *models.User) (string, any, any) {
+				before := map[string]any{"banned": user.Banned(), "banReason": user.BanReason()}
+				user.SetBanned(false, "")
+				return core.AuditUserUnbanned, before, map[string]any{"banned": false}
+			})
+		})
+
+		// An empty role removes the override
+		group.PUT("/role-override", func(e *pbCore.RequestEvent) error {
+			var body struct {
+				Role models.UserRole `json:"role"`
+			}
+			return m.updateAccess(e, &body, func(user *models.User) (string, any, any) {
+				before := map[string]any{"roleOverride": user.RoleOverride()}
+				user.SetRoleOverride(body.Role)
+				return core.AuditUserRoleOverridden, before, map[string]any{"roleOverride": body.Role}
+			}, func() string {
+				switch body.Role {
+				case "", models.RoleGuest, models.RoleUser, models.RoleAdmin:
+					return ""
+				}
+				return "users.role_invalid"
+			})
+		})
+
+		// An empty until revokes the grant
+		group.PUT("/premium-grant", func(e *pbCore.RequestEvent) error {
+			var body struct {
+				Until types.DateTime `json:"until"`
+			}
+			return m.updateAccess(e, &body, func(user *models.User) (string, any, any) {
+				before := map[string]any{"premiumUntil": user.PremiumUntil()}
+				user.SetPremiumUntil(body.Until)
+				return core.AuditUserPremiumGranted, before, map[string]any{"premiumUntil": body.Until}
+			}, func() string {
+				if !body.Until.IsZero() && !body.Until.Time().After(time.Now()) {
+					return "users.premium_until_invalid"
+				}
+				return ""
+			})
+		})
+
+		return se.Next()
+	})
+}
+
+// updateAccess binds the body, runs the validators (returning an i18n key
+// of the error), applies the change, saves and audits it.
+func (m *UsersModule) updateAccess(
+	e *pbCore.RequestEvent,
+	body any,
+	apply func(user *models.User) (action string, before any, after any),
+	validators ...func() string,
+) error {
+	user, err := m.GetUserById(e.Request.PathValue("id"))
+	if err != nil {
+		return e.NotFoundError(m.Ctx.T(e, "users.user_not_found"), err)
+	}
+
+	// Admins can't lock themselves out, superusers can change anyone
+	if !e.HasSuperuserAuth() && e.Auth != nil && e.Auth.Id == user.Id {
+		return e.BadRequestError(m.Ctx.T(e, "users.own_access"), nil)
+	}
+
+	if body != nil {
+		if err := e.BindBody(body); err != nil {
+			return e.BadRequestError(m.Ctx.T(e, "users.body_invalid"), err)
+		}
+	}
+	for _, validate := range validators {
+		if key := validate(); key != "" {
+			return e.BadRequestError(m.Ctx.T(e, key), nil)
+		}
+	}
+
+	action, before, after := apply(user)
+	if err := m.Ctx.App.SaveWithContext(e.Request.Context(), user); err != nil {
+		return e.BadRequestError(m.Ctx.T(e, "users.save_failed"), err)
+	}
+
+	m.Ctx.Audit(core.AuditEntry{
+		Module:   m.Name(),
+		Action:   action,
+		TargetId: user.Id,
+		Before:   before,
+		After:    after,
+	}.WithRequest(e))
+
+	return e.JSON(http.StatusOK, toAccessResponse(user))
+}
+
+func toAccessResponse(user *models.User) accessResponse {
+	return accessResponse{
+		UserId:        user.Id,
+		Role:          user.Role(),
+		Premium:       user.Premium(),
+		MemberRole:    user.MemberRole(),
+		MemberPremium: user.MemberPremium(),
+		Banned:        user.Banned(),
+		BanReason:     user.BanReason(),
+		RoleOverride:  user.RoleOverride(),
+		PremiumUntil:  user.PremiumUntil(),
+	}
+}
@@ -0,0 +1,70 @@
+package users
+
+import (
+	"github.com/docker-pet/backend/core"
+	pbCore "github.com/pocketbase/pocketbase/core"
+)
+
+// bindAuditHooks records role, premium, access override and outline token
+// changes made through the records API (admin dashboard or REST).
+func (m *UsersModule) bindAuditHooks() {
+	m.Ctx.App.OnRecordUpdateRequest("users").BindFunc(func(e *pbCore.RecordRequestEvent) error {
+		original := e.Record.Original()
+		if err := e.Next(); err != nil {
+			return err
+		}
+
+		entries := []core.AuditEntry{}
+		if before, after := original.GetString("role"), e.Record.GetString("role"); before != after {
+			entries = append(entries, core.AuditEntry{
+				Action: core.AuditUserRoleChanged,
+				Before: map[string]any{"role": before},
+				After:  map[string]any{"role": after},
+			})
+		}
+		if before, after := original.GetBool("premium"), e.Record.GetBool("premium"); before != after {
+			entries = append(entries, core.AuditEntry{
+				Action: core.AuditUserPremiumChanged,
+				Before: map[string]any{"premium": before},
+				After:  map[string]any{"premium": after},
+			})
+		}
+		if before, after := original.GetBool("banned"), e.Record.GetBool("banned"); before != after {
+			action := core.AuditUserUnbanned
+			if after {
+				action = core.AuditUserBanned
+			}
+			entries = append(entries, core.AuditEntry{
+				Action: action,
+				Before: map[string]any{"banned": before},
+				After:  map[string]any{"banned": after, "banReason": e.Record.GetString("banReason")},
+			})
+		}
+		if before, after := original.GetString("roleOverride"), e.Record.GetString("roleOverride"); before != after {
+			entries = append(entries, core.AuditEntry{
+				Action: core.AuditUserRoleOverridden,
+				Before: map[string]any{"roleOverride": before},
+				After:  map[string]any{"roleOverride": after},
+			})
+		}
+		if before, after := original.GetString("premiumUntil"), e.Record.GetString("premiumUntil"); before != after {
+			entries = append(entries, core.AuditEntry{
+				Action: core.AuditUserPremiumGranted,
+				Before: map[string]any{"premiumUntil": before},
+				After:  map[string]any{"premiumUntil": after},
+			})
+		}
+		if original.GetString("outlineToken") != e.Record.GetString("outlineToken") {
+			// Never store the token itself
+			entries = append(entries, core.AuditEntry{Action: core.AuditOutlineTokenRotated})
+		}
+
+		for _, entry := range entries {
+			entry.Module = m.Name()
+			entry.TargetId = e.Record.Id
+			m.Ctx.Audit(entry.WithRequest(e.RequestEvent))
+		}
+
+		return nil
+	})
+}
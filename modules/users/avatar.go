@@ -1,55 +1,216 @@
 package users
 
 import (
-	"crypto/md5"
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
 	"fmt"
-	"mime"
+	"image"
+	"image/jpeg"
+	"io"
+	"net/http"
+	"slices"
+	"time"
 
-	"github.com/docker-pet/backend/models"
+	"github.com/disintegration/imaging"
+	"github.com/docker-pet/backend/core"
 	"github.com/pocketbase/pocketbase/tools/filesystem"
+	_ "golang.org/x/image/webp"
 )
 
-func (m *UsersModule) UploadAvatar(user *models.User, url string) bool {
-	hash := fmt.Sprintf("%x", md5.Sum([]byte(url)))
-	if user.AvatarHash() == hash {
-		return false
+// Image types accepted as avatars, detected from the content
+var avatarMimeTypes = []string{"image/jpeg", "image/png", "image/gif", "image/webp"}
+
+// Larger images are rejected before decoding
+const avatarMaxDimension = 4096
+
+// ErrNoAvatar is returned by sources when the user has no avatar.
+var ErrNoAvatar = errors.New("user has no avatar")
+
+// AvatarSource opens the original avatar image, e.g. a download or a
+// Telegram file.
+type AvatarSource func(ctx context.Context) (io.ReadCloser, error)
+
+// FirstAvatar opens the first source that succeeds.
+func FirstAvatar(sources ...AvatarSource) AvatarSource {
+	return func(ctx context.Context) (io.ReadCloser, error) {
+		if len(sources) == 0 {
+			return nil, ErrNoAvatar
+		}
+
+		var errs []error
+		for _, source := range sources {
+			reader, err := source(ctx)
+			if err == nil {
+				return reader, nil
+			}
+			errs = append(errs, err)
+		}
+		return nil, errors.Join(errs...)
 	}
+}
 
-	// Download the avatar image from the provided URL
-	response, err := m.Ctx.HttpClient.R().Get(url)
-	if err != nil {
-		m.Logger.Warn(
-			"Failed to download avatar",
-			"UserId", user.Id,
-			"AvatarUrl", url,
-			"Error", err,
-		)
-		return false
+type avatarJob struct {
+	userId string
+	source AvatarSource
+}
+
+// AvatarFromUrl downloads the avatar with the avatar-fetch HTTP client.
+func (m *UsersModule) AvatarFromUrl(url string) AvatarSource {
+	return func(ctx context.Context) (io.ReadCloser, error) {
+		response, err := m.Ctx.HttpClients.Client(core.HttpProfileAvatarFetch).R().
+			SetContext(ctx).
+			SetDoNotParseResponse(true).
+			Get(url)
+		if err != nil {
+			return nil, err
+		}
+		if response.StatusCode() != http.StatusOK {
+			response.Body.Close()
+			return nil, fmt.Errorf("unexpected status %s", response.Status())
+		}
+		return response.Body, nil
+	}
+}
+
+// QueueAvatar refreshes the avatar in the background, at most once per
+// avatarRefreshInterval for a user. Jobs are dropped while the queue is full.
+func (m *UsersModule) QueueAvatar(userId string, source AvatarSource) {
+	m.avatarMu.Lock()
+	defer m.avatarMu.Unlock()
+
+	if fetched, ok := m.avatarFetched[userId]; ok && time.Since(fetched) < m.Config.AvatarRefreshInterval {
+		return
+	}
+
+	select {
+	case m.avatarQueue <- avatarJob{userId: userId, source: source}:
+		m.avatarFetched[userId] = time.Now()
+	default:
+		m.Logger.Warn("Avatar queue is full, skipping avatar refresh", "UserId", userId)
 	}
+}
 
-	// Detect extension
-	contentType := response.Header().Get("Content-Type")
-	extensions, err := mime.ExtensionsByType(contentType)
-	extension := ".jpg"
-	if err == nil && len(extensions) > 0 {
-		extension = extensions[0]
+// runAvatarWorker processes the queued avatars until ctx is cancelled.
+func (m *UsersModule) runAvatarWorker(ctx context.Context) {
+	prune := time.NewTicker(max(m.Config.AvatarRefreshInterval, time.Minute))
+	defer prune.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-prune.C:
+			m.pruneAvatarFetched()
+		case job := <-m.avatarQueue:
+			m.refreshAvatar(ctx, job)
+		}
 	}
+}
 
-	// Filename
-	filename := fmt.Sprintf("avatar_%s%s", user.Id, extension)
+// refreshAvatar downloads and processes the avatar before loading the user
+// it is saved on, so changes made to the user in the meantime are kept.
+func (m *UsersModule) refreshAvatar(ctx context.Context, job avatarJob) {
+	ctx = core.WithCorrelationId(ctx, core.NewCorrelationId("avatar"))
+	logger := core.Logger(ctx, m.Logger)
+
+	user, err := m.GetUserById(job.userId)
+	if err != nil {
+		return
+	}
 
-	// Create a new file from the downloaded bytes
-	file, err := filesystem.NewFileFromBytes(response.Bytes(), filename)
+	file, hash, err := m.processAvatar(ctx, user.Id, user.AvatarHash(), job.source)
+	if errors.Is(err, ErrNoAvatar) {
+		return
+	}
 	if err != nil {
-		m.Logger.Warn(
-			"Failed to create avatar file from bytes",
-			"UserId", user.Id,
-			"Error", err,
-		)
-		return false
+		logger.Warn("Failed to refresh avatar", "UserId", user.Id, "Error", err)
+		return
+	}
+	if file == nil {
+		return
+	}
+
+	user, err = m.GetUserById(job.userId)
+	if err != nil || user.AvatarHash() == hash {
+		return
 	}
 
-	// Set the avatar for the user
 	user.SetAvatar(file, hash)
-	return true
+	if err := m.Ctx.App.SaveWithContext(ctx, user); err != nil {
+		logger.Error("Failed to save avatar", "UserId", user.Id, "Error", err)
+	}
+}
+
+// pruneAvatarFetched forgets refreshes older than avatarRefreshInterval,
+// they no longer hold back a new one.
+func (m *UsersModule) pruneAvatarFetched() {
+	m.avatarMu.Lock()
+	defer m.avatarMu.Unlock()
+
+	for userId, fetched := range m.avatarFetched {
+		if time.Since(fetched) >= m.Config.AvatarRefreshInterval {
+			delete(m.avatarFetched, userId)
+		}
+	}
+}
+
+// processAvatar reads at most avatarMaxBytes from the source, checks that it
+// is an image and crops and resizes it to a square JPEG. The file is nil
+// when the content has the current hash.
+func (m *UsersModule) processAvatar(ctx context.Context, userId string, currentHash string, source AvatarSource) (*filesystem.File, string, error) {
+	reader, err := source(ctx)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to open avatar: %w", err)
+	}
+	defer reader.Close()
+
+	content, err := io.ReadAll(io.LimitReader(reader, m.Config.AvatarMaxBytes+1))
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to read avatar: %w", err)
+	}
+	if int64(len(content)) > m.Config.AvatarMaxBytes {
+		return nil, "", fmt.Errorf("avatar is larger than %d bytes", m.Config.AvatarMaxBytes)
+	}
+
+	sum := sha256.Sum256(content)
+	hash := hex.EncodeToString(sum[:])
+	if currentHash == hash {
+		return nil, hash, nil
+	}
+
+	if mimeType := http.DetectContentType(content); !slices.Contains(avatarMimeTypes, mimeType) {
+		return nil, "", fmt.Errorf("avatar has unsupported type %s", mimeType)
+	}
+
+	config, _, err := image.DecodeConfig(bytes.NewReader(content))
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to decode avatar: %w", err)
+	}
+	if config.Width > avatarMaxDimension || config.Height > avatarMaxDimension {
+		return nil, "", errors.New("avatar dimensions are too large")
+	}
+
+	img, _, err := image.Decode(bytes.NewReader(content))
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to decode avatar: %w", err)
+	}
+
+	// Square crop without upscaling
+	size := min(config.Width, config.Height, m.Config.AvatarSize)
+	thumb := imaging.Fill(img, size, size, imaging.Center, imaging.Lanczos)
+
+	var buf bytes.Buffer
+	if err := jpeg.Encode(&buf, thumb, &jpeg.Options{Quality: 90}); err != nil {
+		return nil, "", fmt.Errorf("failed to encode avatar: %w", err)
+	}
+
+	file, err := filesystem.NewFileFromBytes(buf.Bytes(), fmt.Sprintf("avatar_%s.jpg", userId))
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to create avatar file: %w", err)
+	}
+
+	return file, hash, nil
 }
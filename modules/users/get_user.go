@@ -1,11 +1,17 @@
 package users
 
 import (
+	"errors"
+	"strconv"
+	"strings"
+
 	"github.com/docker-pet/backend/models"
 	"github.com/pocketbase/dbx"
 	"github.com/pocketbase/pocketbase/core"
 )
 
+var ErrNotAdmin = errors.New("user is not an admin")
+
 func (m *UsersModule) GetUserByTelegramId(telegramId int64) (*models.User, error) {
 	record, err := m.Ctx.App.FindFirstRecordByFilter(
 		"users",
@@ -58,6 +64,44 @@ func (m *UsersModule) FindUsersByFilter(filter string, sort string, limit int, o
 	return lampaUsers, nil
 }
 
+// GetUserByReference finds a user by the record id, the Telegram id or the
+// @username, as typed in bot commands.
+func (m *UsersModule) GetUserByReference(reference string) (*models.User, error) {
+	if username, ok := strings.CutPrefix(reference, "@"); ok {
+		record, err := m.Ctx.App.FindFirstRecordByFilter(
+			"users",
+			"telegramUsername={:username}",
+			dbx.Params{"username": username},
+		)
+		if err != nil {
+			return nil, err
+		}
+		return ProxyUser(record), nil
+	}
+
+	if telegramId, err := strconv.ParseInt(reference, 10, 64); err == nil {
+		return m.GetUserByTelegramId(telegramId)
+	}
+
+	return m.GetUserById(reference)
+}
+
+// GetAdminByTelegramId returns ErrNotAdmin for users without the admin role.
+func (m *UsersModule) GetAdminByTelegramId(telegramId int64) (*models.User, error) {
+	user, err := m.GetUserByTelegramId(telegramId)
+	if err != nil {
+		return nil, err
+	}
+	if user.Role() != models.RoleAdmin {
+		return nil, ErrNotAdmin
+	}
+	return user, nil
+}
+
+func (m *UsersModule) CountUsers(exprs ...dbx.Expression) (int64, error) {
+	return m.Ctx.App.CountRecords("users", exprs...)
+}
+
 func ProxyUser(record *core.Record) *models.User {
 	user := &models.User{}
 	user.SetProxyRecord(record)
@@ -0,0 +1,34 @@
+package users
+
+import (
+	"context"
+)
+
+func (m *UsersModule) Start(ctx context.Context) error {
+	workerCtx, cancel := context.WithCancel(context.Background())
+	m.stopAvatarWorker = cancel
+	m.avatarWorkerDone = make(chan struct{})
+
+	go func() {
+		defer close(m.avatarWorkerDone)
+		m.runAvatarWorker(workerCtx)
+	}()
+
+	return nil
+}
+
+func (m *UsersModule) Stop(ctx context.Context) error {
+	m.Ctx.App.Cron().Remove(cronPremiumExpiryJobId)
+
+	if m.stopAvatarWorker == nil {
+		return nil
+	}
+	m.stopAvatarWorker()
+
+	select {
+	case <-m.avatarWorkerDone:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
@@ -0,0 +1,35 @@
+package users
+
+import (
+	"github.com/docker-pet/backend/core"
+	"github.com/docker-pet/backend/models"
+	"github.com/pocketbase/dbx"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// registerMetrics exposes user counts, queried on every scrape.
+func (m *UsersModule) registerMetrics() {
+	activeRoles := []any{string(models.RoleUser), string(models.RoleAdmin)}
+	states := map[string]dbx.Expression{
+		"active":  dbx.In("role", activeRoles...),
+		"guest":   dbx.NotIn("role", activeRoles...),
+		"premium": dbx.HashExp{"premium": true},
+	}
+
+	for state, expr := range states {
+		m.Ctx.Metrics.MustRegister(prometheus.NewGaugeFunc(prometheus.GaugeOpts{
+			Namespace:   core.MetricsNamespace,
+			Subsystem:   m.Name(),
+			Name:        "count",
+			Help:        "Number of users by state (active, guest, premium).",
+			ConstLabels: prometheus.Labels{"state": state},
+		}, func() float64 {
+			count, err := m.Ctx.App.CountRecords("users", expr)
+			if err != nil {
+				m.Logger.Warn("Failed to count users for metrics", "State", state, "Error", err)
+				return 0
+			}
+			return float64(count)
+		}))
+	}
+}
@@ -0,0 +1,73 @@
+package users
+
+import (
+	"github.com/docker-pet/backend/core"
+	"github.com/docker-pet/backend/models"
+	pbCore "github.com/pocketbase/pocketbase/core"
+)
+
+// publishUserEvents diffs the stored users records and publishes domain
+// events, so other modules don't have to watch the collection themselves.
+func (m *UsersModule) publishUserEvents() {
+	m.Ctx.App.OnRecordAfterCreateSuccess("users").BindFunc(func(e *pbCore.RecordEvent) error {
+		if err := e.Next(); err != nil {
+			return err
+		}
+
+		user := ProxyUser(e.Record)
+		if user.IsActive() {
+			core.Publish(m.Ctx, core.UserActivated{User: user})
+		}
+		if user.Premium() {
+			core.Publish(m.Ctx, core.PremiumChanged{User: user, Premium: true})
+		}
+
+		return nil
+	})
+
+	m.Ctx.App.OnRecordAfterUpdateSuccess("users").BindFunc(func(e *pbCore.RecordEvent) error {
+		if err := e.Next(); err != nil {
+			return err
+		}
+
+		m.publishUserDiff(ProxyUser(e.Record.Original()), ProxyUser(e.Record))
+		return nil
+	})
+
+	m.Ctx.App.OnRecordAfterDeleteSuccess("users").BindFunc(func(e *pbCore.RecordEvent) error {
+		if err := e.Next(); err != nil {
+			return err
+		}
+
+		user := ProxyUser(e.Record)
+		if user.IsActive() {
+			core.Publish(m.Ctx, core.UserDeactivated{User: user, Deleted: true})
+		}
+
+		return nil
+	})
+}
+
+func (m *UsersModule) publishUserDiff(before *models.User, after *models.User) {
+	if !before.IsActive() && after.IsActive() {
+		core.Publish(m.Ctx, core.UserActivated{User: after})
+	}
+	if before.IsActive() && !after.IsActive() {
+		core.Publish(m.Ctx, core.UserDeactivated{User: after})
+	}
+	if before.Premium() != after.Premium() {
+		core.Publish(m.Ctx, core.PremiumChanged{User: after, Premium: after.Premium()})
+	}
+	if !before.AccessPeriodEnds().Equal(after.AccessPeriodEnds()) {
+		core.Publish(m.Ctx, core.AccessExpiryChanged{User: after})
+	}
+	if before.OutlineToken() != after.OutlineToken() {
+		core.Publish(m.Ctx, core.OutlineTokenRotated{User: after})
+	}
+	if before.OutlineServer() != after.OutlineServer() {
+		core.Publish(m.Ctx, core.OutlineServerChanged{
+			User:             after,
+			PreviousServerId: before.OutlineServer(),
+		})
+	}
+}
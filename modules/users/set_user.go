@@ -16,6 +16,7 @@ func (m *UsersModule) NewUser(telegramId int64) (*models.User, error) {
 	user := ProxyUser(record)
 
 	user.SetTelegramId(telegramId)
+	user.SetMemberRole(models.RoleGuest)
 	user.SetRole(models.RoleGuest)
 	user.SetSynced(types.NowDateTime().AddDate(-20, 0, 0))
 	user.SetOutlinePrefixEnabled(false)
@@ -1,17 +1,53 @@
 package users
 
 import (
+	"context"
+	"errors"
 	"log/slog"
+	"sync"
+	"time"
 
 	"github.com/docker-pet/backend/core"
 )
 
-type Config struct{}
+type Config struct {
+	CronPremiumExpiryExpression string        `yaml:"cronPremiumExpiryExpression"` // Cron expression for revoking expired premium grants
+	AvatarMaxBytes              int64         `yaml:"avatarMaxBytes"`              // Larger avatar downloads are rejected
+	AvatarSize                  int           `yaml:"avatarSize"`                  // Side of the stored square avatar, in pixels
+	AvatarRefreshInterval       time.Duration `yaml:"avatarRefreshInterval"`       // Minimum time between avatar refreshes of a user
+	AvatarQueueSize             int           `yaml:"avatarQueueSize"`             // Avatars waiting for the background worker
+}
+
+func (c *Config) Validate() error {
+	var errs []error
+	if c.CronPremiumExpiryExpression == "" {
+		errs = append(errs, errors.New("cronPremiumExpiryExpression is required"))
+	}
+	if c.AvatarMaxBytes <= 0 {
+		errs = append(errs, errors.New("avatarMaxBytes must be positive"))
+	}
+	if c.AvatarSize < 16 {
+		errs = append(errs, errors.New("avatarSize must be at least 16"))
+	}
+	if c.AvatarRefreshInterval < 0 {
+		errs = append(errs, errors.New("avatarRefreshInterval must not be negative"))
+	}
+	if c.AvatarQueueSize < 1 {
+		errs = append(errs, errors.New("avatarQueueSize must be at least 1"))
+	}
+	return errors.Join(errs...)
+}
 
 type UsersModule struct {
 	Ctx    *core.AppContext
 	Config *Config
 	Logger *slog.Logger
+
+	avatarQueue      chan avatarJob
+	avatarMu         sync.Mutex           // Guards avatarFetched
+	avatarFetched    map[string]time.Time // Last queued avatar refresh by user id
+	stopAvatarWorker context.CancelFunc
+	avatarWorkerDone chan struct{}
 }
 
 func (m *UsersModule) Name() string                  { return "users" }
@@ -21,6 +57,15 @@ func (m *UsersModule) Init(ctx *core.AppContext, logger *slog.Logger, cfg any) e
 	m.Ctx = ctx
 	m.Config = cfg.(*Config)
 	m.Logger = logger
+	m.avatarQueue = make(chan avatarJob, m.Config.AvatarQueueSize)
+	m.avatarFetched = map[string]time.Time{}
+
+	m.bindAccessHooks()
+	m.bindAuditHooks()
+	m.usePremiumExpiryCron()
+	m.registerAdminEndpoints()
+	m.publishUserEvents()
+	m.registerMetrics()
 
 	m.Logger.Info("Users module initialized", "Config", m.Config)
 	return nil
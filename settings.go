@@ -0,0 +1,242 @@
+package main
+
+import (
+	"os"
+	"time"
+
+	"github.com/pocketbase/pocketbase/tools/security"
+
+	"github.com/docker-pet/backend/core"
+	"github.com/docker-pet/backend/modules/account"
+	"github.com/docker-pet/backend/modules/app_config"
+	"github.com/docker-pet/backend/modules/bootstrap"
+	"github.com/docker-pet/backend/modules/config_history"
+	"github.com/docker-pet/backend/modules/lampa"
+	"github.com/docker-pet/backend/modules/otp_auth"
+	"github.com/docker-pet/backend/modules/outline"
+	"github.com/docker-pet/backend/modules/secret_keys"
+	"github.com/docker-pet/backend/modules/subscriptions"
+	"github.com/docker-pet/backend/modules/telegram_bot"
+	"github.com/docker-pet/backend/modules/telegram_miniapp"
+	"github.com/docker-pet/backend/modules/translations"
+	"github.com/docker-pet/backend/modules/users"
+)
+
+const (
+	settingsPathEnv   = "DOCKER_PET_CONFIG"
+	settingsEnvPrefix = "DOCKER_PET"
+)
+
+type Settings struct {
+	ShutdownTimeout time.Duration `yaml:"shutdownTimeout"`
+	MetricsSecret   string        `yaml:"metricsSecret"`  // Bearer token for GET /metrics, generated on start if empty
+	FallbackLocale  string        `yaml:"fallbackLocale"` // Locale used when the user's language has no catalog
+
+	HttpClients struct {
+		Default     core.HttpClientProfile `yaml:"default"`
+		CaddyAdmin  core.HttpClientProfile `yaml:"caddyAdmin"`
+		AvatarFetch core.HttpClientProfile `yaml:"avatarFetch"`
+		Metrics     core.HttpClientProfile `yaml:"metrics"`
+	} `yaml:"httpClients"`
+
+	Modules struct {
+		AppConfig       core.ModuleSettings[app_config.Config]       `yaml:"app_config"`
+		Users           core.ModuleSettings[users.Config]            `yaml:"users"`
+		Lampa           core.ModuleSettings[lampa.Config]            `yaml:"lampa"`
+		OtpAuth         core.ModuleSettings[otp_auth.Config]         `yaml:"otp_auth"`
+		TelegramBot     core.ModuleSettings[telegram_bot.Config]     `yaml:"telegram_bot"`
+		TelegramMiniapp core.ModuleSettings[telegram_miniapp.Config] `yaml:"telegram_miniapp"`
+		Outline         core.ModuleSettings[outline.Config]          `yaml:"outline"`
+		ConfigHistory   core.ModuleSettings[config_history.Config]   `yaml:"config_history"`
+		SecretKeys      core.ModuleSettings[secret_keys.Config]      `yaml:"secret_keys"`
+		Translations    core.ModuleSettings[translations.Config]     `yaml:"translations"`
+		Bootstrap       core.ModuleSettings[bootstrap.Config]        `yaml:"bootstrap"`
+		Account         core.ModuleSettings[account.Config]          `yaml:"account"`
+		Subscriptions   core.ModuleSettings[subscriptions.Config]    `yaml:"subscriptions"`
+	} `yaml:"modules"`
+}
+
+func defaultSettings() *Settings {
+	s := &Settings{
+		ShutdownTimeout: time.Second * 15,
+		FallbackLocale:  "ru",
+	}
+
+	s.HttpClients.Default = core.DefaultHttpClientProfile()
+	s.HttpClients.CaddyAdmin = core.HttpClientProfile{
+		Timeout:           time.Second * 15,
+		RetryCount:        3,
+		RetryWaitTime:     time.Second,
+		RetryMaxWaitTime:  time.Second * 5,
+		ResponseBodyLimit: 8 << 20,
+	}
+	s.HttpClients.AvatarFetch = core.HttpClientProfile{
+		Timeout:           time.Second * 10,
+		RetryCount:        2,
+		RetryWaitTime:     time.Second,
+		RetryMaxWaitTime:  time.Second * 3,
+		ResponseBodyLimit: 5 << 20,
+	}
+	s.HttpClients.Metrics = core.HttpClientProfile{
+		Timeout:           time.Second * 10,
+		ResponseBodyLimit: 16 << 20,
+	}
+
+	s.Modules.AppConfig.Enabled = true
+	s.Modules.AppConfig.Config = app_config.Config{
+		TelegramApiUrl: "https://api.telegram.org",
+		LiveValidation: true,
+	}
+
+	s.Modules.Users.Enabled = true
+	s.Modules.Users.Config = users.Config{
+		CronPremiumExpiryExpression: "*/5 * * * *",
+		AvatarMaxBytes:              5 << 20,
+		AvatarSize:                  512,
+		AvatarRefreshInterval:       time.Hour * 6,
+		AvatarQueueSize:             100,
+	}
+
+	s.Modules.Lampa.Enabled = true
+	s.Modules.Lampa.Config = lampa.Config{
+		StoragePath: "./generated/lampa",
+	}
+
+	s.Modules.OtpAuth.Enabled = true
+	s.Modules.OtpAuth.Config = otp_auth.Config{
+		SessionVerifyInterval:             time.Minute * 7,
+		AuthSessionLifetime:               time.Minute * 5,
+		ExpiredAuthSessionCleanupInterval: time.Minute * 15,
+		MaxPinGenerationAttempts:          10,
+	}
+
+	s.Modules.TelegramBot.Enabled = true
+	s.Modules.TelegramBot.Config = telegram_bot.Config{
+		CronUserSyncExpression: "*/15 * * * *",
+		CronUserSyncInterval:   time.Minute * 60,
+		CronUsersPerSync:       10,
+		PollerMode:             telegram_bot.PollerAuto,
+		LongPollTimeout:        time.Second * 30,
+	}
+
+	s.Modules.TelegramMiniapp.Enabled = true
+	s.Modules.TelegramMiniapp.Config = telegram_miniapp.Config{
+		AuthTokenLifetime: time.Hour * 12,
+	}
+
+	s.Modules.Outline.Enabled = true
+	s.Modules.Outline.Config = outline.Config{
+		OutlineStoragePath:        "./generated/outline",
+		OutlineCipher:             "chacha20-ietf-poly1305",
+		OutlineTechnicalKeyName:   "service",
+		OutlineTechnicalKeySecret: security.RandomString(32),
+
+		PrometheusStoragePath:       "./generated/prometheus",
+		PrometheusJobName:           "outline",
+		PrometheusJobManagedByLabel: "github.com/docker-pet",
+		PrometheusBackendJobName:    "backend",
+
+		CaddyCloudflareApiToken: os.Getenv("CLOUDFLARE_API_TOKEN"),
+	}
+
+	s.Modules.ConfigHistory.Enabled = true
+	s.Modules.ConfigHistory.Config = config_history.Config{
+		ListLimit: 100,
+	}
+
+	s.Modules.SecretKeys.Enabled = true
+	s.Modules.SecretKeys.Config = secret_keys.Config{
+		AuthSecretGracePeriod:    time.Hour * 24 * 30,
+		MetricsSecretGracePeriod: time.Hour,
+		CronCleanupExpression:    "0 * * * *",
+	}
+
+	s.Modules.Translations.Enabled = true
+
+	s.Modules.Bootstrap.Enabled = true
+
+	s.Modules.Account.Enabled = true
+	s.Modules.Account.Config = account.Config{
+		DeleteConfirmationLifetime: time.Minute * 10,
+	}
+
+	s.Modules.Subscriptions.Enabled = true
+	s.Modules.Subscriptions.Config = subscriptions.Config{
+		CronExpiryExpression: "*/5 * * * *",
+		NotifyBefore:         time.Hour * 72,
+	}
+
+	return s
+}
+
+// loadSettings reads the settings file pointed by DOCKER_PET_CONFIG
+// (./config.yaml by default) on top of the defaults and applies
+// DOCKER_PET_* environment overrides.
+func loadSettings() (*Settings, error) {
+	path := os.Getenv(settingsPathEnv)
+	if path == "" {
+		path = "./config.yaml"
+	}
+
+	settings := defaultSettings()
+	if err := core.LoadConfig(path, settingsEnvPrefix, settings); err != nil {
+		return nil, err
+	}
+
+	return settings, nil
+}
+
+// httpClientProfiles maps the httpClients section to the profile names.
+func (s *Settings) httpClientProfiles() map[string]core.HttpClientProfile {
+	return map[string]core.HttpClientProfile{
+		core.HttpProfileDefault:     s.HttpClients.Default,
+		core.HttpProfileCaddyAdmin:  s.HttpClients.CaddyAdmin,
+		core.HttpProfileAvatarFetch: s.HttpClients.AvatarFetch,
+		core.HttpProfileMetrics:     s.HttpClients.Metrics,
+	}
+}
+
+// registerModules registers every enabled module with its config.
+func registerModules(settings *Settings) {
+	modules := &settings.Modules
+
+	if modules.AppConfig.Enabled {
+		core.RegisterModule(&app_config.AppConfigModule{}, &modules.AppConfig.Config)
+	}
+	if modules.Users.Enabled {
+		core.RegisterModule(&users.UsersModule{}, &modules.Users.Config)
+	}
+	if modules.Lampa.Enabled {
+		core.RegisterModule(&lampa.LampaModule{}, &modules.Lampa.Config)
+	}
+	if modules.OtpAuth.Enabled {
+		core.RegisterModule(&otp_auth.OtpAuthModule{}, &modules.OtpAuth.Config)
+	}
+	if modules.TelegramBot.Enabled {
+		core.RegisterModule(&telegram_bot.TelegramBotModule{}, &modules.TelegramBot.Config)
+	}
+	if modules.TelegramMiniapp.Enabled {
+		core.RegisterModule(&telegram_miniapp.TelegramMiniappModule{}, &modules.TelegramMiniapp.Config)
+	}
+	if modules.Outline.Enabled {
+		core.RegisterModule(&outline.OutlineModule{}, &modules.Outline.Config)
+	}
+	if modules.ConfigHistory.Enabled {
+		core.RegisterModule(&config_history.ConfigHistoryModule{}, &modules.ConfigHistory.Config)
+	}
+	if modules.SecretKeys.Enabled {
+		core.RegisterModule(&secret_keys.SecretKeysModule{}, &modules.SecretKeys.Config)
+	}
+	if modules.Translations.Enabled {
+		core.RegisterModule(&translations.TranslationsModule{}, &modules.Translations.Config)
+	}
+	if modules.Bootstrap.Enabled {
+		core.RegisterModule(&bootstrap.BootstrapModule{}, &modules.Bootstrap.Config)
+	}
+	if modules.Account.Enabled {
+		core.RegisterModule(&account.AccountModule{}, &modules.Account.Config)
+	}
+	if modules.Subscriptions.Enabled {
+		core.RegisterModule(&subscriptions.SubscriptionsModule{}, &modules.Subscriptions.Config)
+	}
+}